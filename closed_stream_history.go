@@ -0,0 +1,43 @@
+package quic
+
+// closedStreamHistory tracks the closed streams opened by the peer, so late frames don't open them
+// again. Since streams are closed in roughly ascending order, the ids below a watermark are
+// considered closed and only the closed ids above it are stored. It's not safe for concurrent use.
+type closedStreamHistory struct {
+	closedBelow uint32
+	closed      map[uint32]struct{}
+}
+
+// newClosedStreamHistory returns a history for the streams starting at the provided id. All ids
+// of a history have the same parity.
+func newClosedStreamHistory(firstStreamID uint32) closedStreamHistory {
+	return closedStreamHistory{
+		closedBelow: firstStreamID,
+		closed:      make(map[uint32]struct{}),
+	}
+}
+
+// isClosed returns true, if the stream with the provided id has been closed.
+func (h *closedStreamHistory) isClosed(id uint32) bool {
+	if id < h.closedBelow {
+		return true
+	}
+	_, ok := h.closed[id]
+	return ok
+}
+
+// close records the stream with the provided id as closed.
+func (h *closedStreamHistory) close(id uint32) {
+	if id < h.closedBelow {
+		return
+	}
+	h.closed[id] = struct{}{}
+
+	for {
+		if _, ok := h.closed[h.closedBelow]; !ok {
+			return
+		}
+		delete(h.closed, h.closedBelow)
+		h.closedBelow += 2
+	}
+}
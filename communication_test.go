@@ -1,12 +1,24 @@
 package quic_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/simia-tech/go-quic"
+	"github.com/simia-tech/go-quic/frame"
+	"github.com/simia-tech/go-quic/handshake"
+	"github.com/simia-tech/go-quic/packet"
 )
 
 func TestClientServerEcho(t *testing.T) {
@@ -34,3 +46,966 @@ func TestClientServerEcho(t *testing.T) {
 
 	assert.Equal(t, "test", line)
 }
+
+func TestClientServerLargeTransfer(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.Listen(serverConn, 3)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	go func() {
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+
+		buffer := make([]byte, len(data))
+		_, err = io.ReadFull(conn, buffer)
+		require.NoError(t, err)
+		_, err = conn.Write(buffer)
+		require.NoError(t, err)
+	}()
+
+	clientConn := DialUDP(t, serverConn.LocalAddr())
+
+	conn, err := quic.Dial(clientConn, 3)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+
+	buffer := make([]byte, len(data))
+	_, err = io.ReadFull(conn, buffer)
+	require.NoError(t, err)
+
+	assert.Equal(t, data, buffer)
+}
+
+func TestSessionMultipleStreams(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		for {
+			stream, err := session.AcceptStream()
+			if err != nil {
+				return
+			}
+			go func() {
+				line := ReadLine(t, stream)
+				WriteLine(t, stream, fmt.Sprintf("%d:%s", stream.StreamID(), line))
+			}()
+		}
+	}()
+
+	clientConn := DialUDP(t, serverConn.LocalAddr())
+
+	session, err := quic.DialSession(clientConn)
+	require.NoError(t, err)
+	defer session.Close()
+
+	streams := make([]*quic.Stream, 3)
+	for index := range streams {
+		streams[index], err = session.OpenStreamSync()
+		require.NoError(t, err)
+		WriteLine(t, streams[index], "test")
+	}
+
+	for _, stream := range streams {
+		line := ReadLine(t, stream)
+		assert.Equal(t, fmt.Sprintf("%d:test", stream.StreamID()), line)
+	}
+	assert.Equal(t, []uint32{3, 5, 7}, []uint32{streams[0].StreamID(), streams[1].StreamID(), streams[2].StreamID()})
+}
+
+func TestSessionReorderedStreams(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	// The first frame of stream 5 arrives before the one of stream 3, which opens both streams.
+	WritePacket(t, conn, 1, 1, StreamFrame(5, 0, "b"))
+	WritePacket(t, conn, 1, 2, StreamFrame(3, 0, "a"))
+
+	session, err := listener.Accept()
+	require.NoError(t, err)
+	for _, expected := range []string{"a", "b"} {
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		buffer := make([]byte, 1)
+		_, err = io.ReadFull(stream, buffer)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buffer))
+		require.NoError(t, stream.Close())
+	}
+
+	// Late frames of closed streams don't open them again.
+	WritePacket(t, conn, 1, 3, StreamFrame(3, 2, "c"))
+	WritePacket(t, conn, 1, 4, StreamFrame(7, 0, "d"))
+	stream, err := session.AcceptStream()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), stream.StreamID())
+}
+
+func TestSessionTooManyOpenStreams(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	// Stream 203 implicitly opens the streams 3 to 201, which exceeds the limit of 100 streams.
+	WritePacket(t, conn, 1, 1, StreamFrame(203, 0, "a"))
+
+	session, err := listener.Accept()
+	require.NoError(t, err)
+	_, err = session.AcceptStream()
+	assert.Equal(t, &quic.ConnectionError{ErrorCode: quic.TooManyOpenStreams}, err)
+}
+
+func TestListenerMultipleClients(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.Listen(serverConn, 3)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line := ReadLine(t, conn)
+				WriteLine(t, conn, line)
+			}()
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for index := 0; index < 10; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			conn, err := quic.Dial(DialUDP(t, serverConn.LocalAddr()), 3)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			message := fmt.Sprintf("client %d", index)
+			WriteLine(t, conn, message)
+			assert.Equal(t, message, ReadLine(t, conn))
+		}(index)
+	}
+	wg.Wait()
+}
+
+func TestStreamCloseWrite(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		defer stream.Close()
+
+		request, err := ioutil.ReadAll(stream)
+		require.NoError(t, err)
+		_, err = stream.Write(append([]byte("response to "), request...))
+		require.NoError(t, err)
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseWrite())
+
+	_, err = stream.Write([]byte("more"))
+	assert.Equal(t, quic.ErrStreamClosed, err)
+
+	response, err := ioutil.ReadAll(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "response to request", string(response))
+}
+
+func TestStreamCancelWrite(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+
+		_, err = ioutil.ReadAll(stream)
+		errs <- err
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, stream.CancelWrite(7))
+
+	_, err = stream.Write([]byte("more"))
+	assert.Equal(t, quic.ErrStreamClosed, err)
+
+	assert.Equal(t, &quic.StreamResetError{StreamID: 3, ErrorCode: 7}, <-errs)
+}
+
+func TestStreamResetFlowControl(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(3), stream.StreamID())
+
+		_, err = session.AcceptStream()
+		errs <- err
+	}()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	// The reset of an unknown stream doesn't open it.
+	WritePacket(t, conn, 1, 1, ResetStreamFrame(5, 0, 7))
+	WritePacket(t, conn, 1, 2, StreamFrame(3, 0, "a"))
+
+	// The final offset exceeds the stream's flow control window.
+	WritePacket(t, conn, 1, 3, ResetStreamFrame(3, 1<<20, 7))
+
+	assert.Equal(t, &quic.ConnectionError{ErrorCode: quic.FlowControlReceivedTooMuchData}, <-errs)
+}
+
+func TestStreamReadAfterSessionClose(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		_, err = stream.Write([]byte("partial"))
+		require.NoError(t, err)
+
+		// The session is closed without an error, but the stream hasn't been finished.
+		require.NoError(t, session.Close())
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	response, err := ioutil.ReadAll(stream)
+	assert.Equal(t, "partial", string(response))
+	assert.Equal(t, &quic.ConnectionError{ErrorCode: quic.NoError, Remote: true}, err)
+}
+
+func TestSessionCloseWithError(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		_, err = session.AcceptStream()
+		require.NoError(t, err)
+
+		_, err = session.AcceptStream()
+		errs <- err
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	require.NoError(t, session.CloseWithError(quic.PeerGoingAway, "restart"))
+
+	_, err = session.OpenStream()
+	assert.Equal(t, &quic.ConnectionError{ErrorCode: quic.PeerGoingAway, ReasonPhrase: "restart"}, err)
+
+	assert.Equal(t, &quic.ConnectionError{ErrorCode: quic.PeerGoingAway, ReasonPhrase: "restart", Remote: true}, <-errs)
+}
+
+func TestListenerShutdown(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	accepted := make(chan struct{})
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		close(accepted)
+
+		request, err := ioutil.ReadAll(stream)
+		require.NoError(t, err)
+		_, err = stream.Write(append([]byte("response to "), request...))
+		require.NoError(t, err)
+		require.NoError(t, stream.Close())
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+	<-accepted
+
+	shutdownErrs := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownErrs <- listener.Shutdown(ctx)
+	}()
+
+	// The stream opened before the go away frame has been sent is finished, new streams are rejected.
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if _, err = session.OpenStream(); err == quic.ErrGoAway {
+			break
+		}
+	}
+	require.Equal(t, quic.ErrGoAway, err)
+	assert.True(t, err.(net.Error).Temporary())
+
+	require.NoError(t, stream.CloseWrite())
+	response, err := ioutil.ReadAll(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "response to request", string(response))
+
+	assert.NoError(t, <-shutdownErrs)
+}
+
+func TestSessionGoAwayResetsNewStreams(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	WritePacket(t, conn, 1, 1, StreamFrame(3, 0, "a"))
+	session, err := listener.Accept()
+	require.NoError(t, err)
+	_, err = session.AcceptStream()
+	require.NoError(t, err)
+	require.NoError(t, session.GoAway())
+
+	// The client missed the go away frame and opens another stream, which gets reset.
+	WritePacket(t, conn, 1, 2, StreamFrame(5, 0, "b"))
+	rs := ReadResetStream(t, conn)
+	assert.Equal(t, uint32(5), rs.StreamID())
+	assert.Equal(t, uint64(1), rs.ByteOffset())
+	assert.Equal(t, uint32(quic.PeerGoingAway), rs.ErrorCode())
+}
+
+func TestStreamFlowControl(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	data := bytes.Repeat([]byte("0123456789"), 20000)
+
+	received := make(chan []byte, 1)
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+
+		// Read slowly, so the writer gets blocked by the flow control windows.
+		buffer := &bytes.Buffer{}
+		chunk := make([]byte, 4096)
+		for {
+			n, err := stream.Read(chunk)
+			buffer.Write(chunk[:n])
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			time.Sleep(time.Millisecond)
+		}
+		received <- buffer.Bytes()
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+
+	n, err := stream.Write(data)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	require.NoError(t, stream.CloseWrite())
+
+	assert.Equal(t, data, <-received)
+}
+
+func TestStreamCloseReturnsUnreadData(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		// Only the first byte of each stream is read, the rest is dropped by Close.
+		for {
+			stream, err := session.AcceptStream()
+			if err != nil {
+				return
+			}
+			_, err = io.ReadFull(stream, make([]byte, 1))
+			require.NoError(t, err)
+			require.NoError(t, stream.Close())
+		}
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	// Without returning the dropped data, the connection's window would be exhausted after four
+	// streams.
+	for i := 0; i < 10; i++ {
+		stream, err := session.OpenStream()
+		require.NoError(t, err)
+		require.NoError(t, stream.SetWriteDeadline(time.Now().Add(2*time.Second)))
+
+		_, err = stream.Write(make([]byte, 4000))
+		if err != nil {
+			assert.Equal(t, &quic.StreamResetError{StreamID: stream.StreamID(), ErrorCode: quic.StreamCancelled}, err)
+		}
+	}
+}
+
+func TestStreamFlowControlBlocksWriter(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	require.NoError(t, stream.SetWriteDeadline(time.Now().Add(200*time.Millisecond)))
+
+	// Nobody reads on the server side, so only the initial window of 16 KiB can be written.
+	n, err := stream.Write(make([]byte, 32*1024))
+	assert.Equal(t, quic.ErrTimeout, err)
+	assert.Equal(t, 16*1024, n)
+}
+
+func TestSessionRetransmission(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(&LossyPacketConn{PacketConn: serverConn, dropper: dropper{N: 5}})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	data := bytes.Repeat([]byte("0123456789"), 5000)
+
+	received := make(chan struct{}, 1)
+	errs := make(chan error, 1)
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		request, err := ioutil.ReadAll(stream)
+		require.NoError(t, err)
+		_, err = stream.Write(request)
+		require.NoError(t, err)
+		require.NoError(t, stream.CloseWrite())
+
+		stream, err = session.AcceptStream()
+		require.NoError(t, err)
+		_, err = io.ReadFull(stream, make([]byte, 7))
+		require.NoError(t, err)
+		received <- struct{}{}
+		_, err = ioutil.ReadAll(stream)
+		errs <- err
+	}()
+
+	// Lost stream frames, fins, window updates and resets are sent again.
+	session, err := quic.DialSession(&LossyConn{Conn: DialUDP(t, serverConn.LocalAddr()), dropper: dropper{N: 4}})
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	require.NoError(t, stream.SetDeadline(time.Now().Add(10*time.Second)))
+	_, err = stream.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseWrite())
+
+	response, err := ioutil.ReadAll(stream)
+	require.NoError(t, err)
+	assert.Equal(t, data, response)
+
+	stream, err = session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	// A reset that overtakes the stream's first frame would close the stream before it's accepted.
+	<-received
+	require.NoError(t, stream.CancelWrite(7))
+	assert.Equal(t, &quic.StreamResetError{StreamID: stream.StreamID(), ErrorCode: 7}, <-errs)
+}
+
+func TestSessionAcknowledgesPackets(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	WritePacket(t, conn, 1, 1, StreamFrame(3, 0, "a"))
+	WritePacket(t, conn, 1, 2, StreamFrame(3, 1, "b"))
+	ack := ReadAcknowledge(t, conn)
+	assert.Equal(t, uint64(2), ack.LargestAcked())
+	assert.Equal(t, []frame.AckRange{{Smallest: 1, Largest: 2}}, ack.AckRanges())
+
+	// The stop waiting frame tells the server to stop acknowledging the packets below 4.
+	stopWaiting := frame.StopWaiting(make([]byte, frame.StopWaitingLen(1)))
+	stopWaiting.SetLeastUnackedDelta(0, 1)
+	WritePacket(t, conn, 1, 4, stopWaiting, StreamFrame(3, 2, "c"))
+	WritePacket(t, conn, 1, 5, StreamFrame(3, 3, "d"))
+	ack = ReadAcknowledge(t, conn)
+	assert.Equal(t, uint64(5), ack.LargestAcked())
+	assert.Equal(t, []frame.AckRange{{Smallest: 4, Largest: 5}}, ack.AckRanges())
+}
+
+func TestListenerPeerAddressChange(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+	otherConn := DialUDP(t, serverConn.LocalAddr())
+	defer otherConn.Close()
+
+	ping := frame.Ping(make([]byte, 1))
+	ping.Set()
+
+	WritePacket(t, conn, 1, 2, StreamFrame(3, 0, "a"))
+	WritePacket(t, conn, 1, 3, StreamFrame(3, 1, "b"))
+	ack := ReadAcknowledge(t, conn)
+	for ack.LargestAcked() < 3 {
+		ack = ReadAcknowledge(t, conn)
+	}
+
+	// A packet below the largest received packet number doesn't move the session to its address,
+	// so it's acknowledged to the original address.
+	WritePacket(t, otherConn, 1, 1, ping)
+	ack = ReadAcknowledge(t, conn)
+	assert.Equal(t, []frame.AckRange{{Smallest: 1, Largest: 3}}, ack.AckRanges())
+
+	require.NoError(t, otherConn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, err = otherConn.Read(make([]byte, 1500))
+	require.Error(t, err)
+	assert.True(t, err.(net.Error).Timeout(), "got %v", err)
+
+	// A new largest packet number moves the session to the packet's address.
+	WritePacket(t, otherConn, 1, 4, ping)
+	assert.Equal(t, uint64(4), ReadAcknowledge(t, otherConn).LargestAcked())
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.SetIdleTimeout(100*time.Millisecond))
+
+	_, err = session.AcceptStream()
+	assert.True(t, errors.Is(err, quic.NetworkIdleTimeout), "got %v", err)
+}
+
+func TestSessionIdleTimeoutDisabled(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.SetIdleTimeout(100*time.Millisecond))
+	require.NoError(t, session.SetIdleTimeout(0))
+
+	time.Sleep(300 * time.Millisecond)
+
+	_, err = session.OpenStream()
+	assert.NoError(t, err)
+}
+
+func TestSessionKeepAlive(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.SetIdleTimeout(200*time.Millisecond))
+	require.NoError(t, session.SetKeepAlive(true))
+
+	// The pings are acknowledged by the server, so the session outlives the idle timeout.
+	time.Sleep(600 * time.Millisecond)
+
+	_, err = session.OpenStream()
+	assert.NoError(t, err)
+}
+
+func TestListenerPublicReset(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	// A packet without version can't open a connection, so the unknown connection is reset.
+	regular := packet.Regular(make([]byte, 1+8+1+1))
+	regular.AddConnectionID(1)
+	regular.AddPacketNumber(7, 1)
+	regular.SetData([]byte{frame.TypePing})
+	_, err = conn.Write(regular)
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buffer := make([]byte, 1500)
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+
+	pr, err := packet.ParsePublicReset(buffer[:n])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), pr.ConnectionID())
+	assert.Equal(t, uint64(7), pr.RejectedPacketNumber())
+	assert.Equal(t, conn.LocalAddr().String(), pr.ClientAddress().String())
+}
+
+func TestSessionPublicReset(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+	defer serverConn.Close()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	buffer := make([]byte, 1500)
+	n, addr, err := serverConn.ReadFrom(buffer)
+	require.NoError(t, err)
+	p, err := packet.Parse(buffer[:n], packet.PerspectiveClient)
+	require.NoError(t, err)
+	connectionID := p.ConnectionID()
+
+	writePublicReset := func(connectionID, rejectedPacketNumber uint64) {
+		pr := packet.PublicReset(make([]byte, packet.PublicResetLen(nil)))
+		pr.SetConnectionID(connectionID)
+		pr.SetMessage(1, rejectedPacketNumber, nil)
+		_, err := serverConn.WriteTo(pr, addr)
+		require.NoError(t, err)
+	}
+
+	// Public resets for other connections or for packets that haven't been sent are ignored.
+	writePublicReset(connectionID+1, 1)
+	writePublicReset(connectionID, 100)
+	time.Sleep(50 * time.Millisecond)
+	_, err = session.OpenStream()
+	require.NoError(t, err)
+
+	writePublicReset(connectionID, 1)
+	_, err = session.AcceptStream()
+	assert.Equal(t, quic.ErrPublicReset, err)
+}
+
+func TestSessionVersionNegotiation(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+	require.NoError(t, listener.SetVersions([]quic.Version{quic.VersionQ036, quic.VersionQ035}))
+
+	versions := make(chan quic.Version, 1)
+	go func() {
+		session, err := listener.Accept()
+		require.NoError(t, err)
+		versions <- session.Version()
+
+		stream, err := session.AcceptStream()
+		require.NoError(t, err)
+		request, err := ioutil.ReadAll(stream)
+		require.NoError(t, err)
+		_, err = stream.Write(append([]byte("response to "), request...))
+		require.NoError(t, err)
+		require.NoError(t, stream.Close())
+	}()
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.SetVersions([]quic.Version{quic.VersionQ037, quic.VersionQ035, quic.VersionQ036}))
+
+	// The request is sent with the first version, dropped by the server and sent again with the
+	// best common version.
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseWrite())
+
+	response, err := ioutil.ReadAll(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "response to request", string(response))
+	assert.Equal(t, quic.VersionQ035, session.Version())
+	assert.Equal(t, quic.VersionQ035, <-versions)
+
+	assert.Equal(t, quic.ErrVersionInUse, session.SetVersions([]quic.Version{quic.VersionQ037}))
+}
+
+func TestSessionVersionNegotiationWithoutCommonVersion(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+	require.NoError(t, listener.SetVersions([]quic.Version{quic.VersionQ035}))
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.SetVersions([]quic.Version{quic.VersionQ037, quic.VersionQ036}))
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	_, err = session.AcceptStream()
+	assert.Equal(t, quic.ErrVersionNegotiation, err)
+}
+
+func TestSessionVersionNegotiationDowngrade(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+	defer serverConn.Close()
+	require.NoError(t, serverConn.SetReadDeadline(time.Now().Add(2*time.Second)))
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+
+	stream, err := session.OpenStream()
+	require.NoError(t, err)
+	_, err = stream.Write([]byte("request"))
+	require.NoError(t, err)
+
+	buffer := make([]byte, 1500)
+	readRegular := func() (packet.Regular, net.Addr) {
+		n, addr, err := serverConn.ReadFrom(buffer)
+		require.NoError(t, err)
+		regular, err := packet.ParseRegular(buffer[:n])
+		require.NoError(t, err)
+		return regular, addr
+	}
+	regular, addr := readRegular()
+	connectionID := regular.ConnectionID()
+	assert.Equal(t, quic.VersionQ037, regular.Version())
+
+	writeVersionNegotiation := func(connectionID uint64, versions ...quic.Version) {
+		vn := packet.VersionNegotiation(make([]byte, 9+4*len(versions)))
+		vn.SetConnectionID(connectionID)
+		vn.SetVersions(versions)
+		_, err := serverConn.WriteTo(vn, addr)
+		require.NoError(t, err)
+	}
+
+	// Packets that offer the version in use or belong to another connection are ignored.
+	writeVersionNegotiation(connectionID, quic.VersionQ036, quic.VersionQ037)
+	writeVersionNegotiation(connectionID+1, quic.VersionQ036)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, quic.VersionQ037, session.Version())
+
+	writeVersionNegotiation(connectionID, quic.VersionQ035, quic.VersionQ036)
+	regular, _ = readRegular()
+	assert.Equal(t, quic.VersionQ036, regular.Version())
+	assert.Equal(t, quic.VersionQ036, session.Version())
+	sf, err := frame.ParseStream(regular.Data())
+	require.NoError(t, err)
+	assert.Equal(t, "request", string(sf.Data()))
+
+	// The originally offered version is sent to the server, so it can detect the downgrade.
+	regular, _ = readRegular()
+	sf, err = frame.ParseStream(regular.Data())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), sf.StreamID())
+	message := handshake.Message{}
+	require.NoError(t, message.Unmarshal(sf.Data()))
+	assert.Equal(t, handshake.TagCHLO, message.Tag)
+	value, _ := message.Get(handshake.TagVER)
+	assert.Equal(t, []byte("Q037"), value)
+
+	// The version can only be negotiated once.
+	writeVersionNegotiation(connectionID, quic.VersionQ035)
+	_, err = session.AcceptStream()
+	assert.Equal(t, quic.ErrVersionNegotiation, err)
+}
+
+func TestListenerVersionNegotiationMismatch(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+	require.NoError(t, listener.SetVersions([]quic.Version{quic.VersionQ037, quic.VersionQ036}))
+
+	conn := DialUDP(t, serverConn.LocalAddr())
+	defer conn.Close()
+
+	// An offered version that the server doesn't support has been negotiated legitimately.
+	WritePacket(t, conn, 1, 1, ClientHelloFrame(t, quic.VersionQ035), StreamFrame(3, 0, "a"))
+	session, err := listener.Accept()
+	require.NoError(t, err)
+	_, err = session.AcceptStream()
+	require.NoError(t, err)
+
+	// The server would have accepted the offered version, so the negotiation has been tampered
+	// with.
+	WritePacket(t, conn, 1, 2, ClientHelloFrame(t, quic.VersionQ036))
+	_, err = session.AcceptStream()
+	assert.Equal(t, &quic.ConnectionError{ErrorCode: quic.VersionNegotiationMismatch}, err)
+}
+
+func TestSetVersions(t *testing.T) {
+	serverConn := ListenUDP(t, "localhost:0")
+
+	listener, err := quic.ListenSession(serverConn)
+	require.NoError(t, err)
+	defer listener.Close()
+	assert.Equal(t, quic.ErrUnsupportedVersion, listener.SetVersions(nil))
+	assert.Equal(t, quic.ErrUnsupportedVersion, listener.SetVersions([]quic.Version{quic.VersionQ037, 0xff00001d}))
+	assert.Equal(t, quic.ErrUnsupportedVersion, listener.SetVersions([]quic.Version{packet.VersionQ039}))
+
+	session, err := quic.DialSession(DialUDP(t, serverConn.LocalAddr()))
+	require.NoError(t, err)
+	defer session.Close()
+	assert.Equal(t, quic.ErrUnsupportedVersion, session.SetVersions([]quic.Version{}))
+	assert.Equal(t, quic.ErrUnsupportedVersion, session.SetVersions([]quic.Version{0xff00001d}))
+	assert.Equal(t, quic.VersionQ037, session.Version())
+}
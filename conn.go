@@ -1,7 +1,60 @@
 package quic
 
-import "net"
+import (
+	"errors"
+	"net"
+	"syscall"
 
+	"github.com/simia-tech/go-quic/packet"
+)
+
+// DialSession establishes a quic session over the provided conn. The session takes ownership of conn
+// and closes it when it gets closed.
+func DialSession(conn net.Conn) (*Session, error) {
+	connectionID, err := newConnectionID()
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSession(packet.PerspectiveClient, connectionID, &connTransport{conn: conn})
+	go readLoop(conn, s)
+
+	return s, nil
+}
+
+// Dial establishes a quic session over the provided conn and returns a net.Conn for the stream
+// with the provided id. Closing the returned net.Conn closes the whole session.
 func Dial(conn net.Conn, streamID int) (net.Conn, error) {
-	return nil, nil
+	s, err := DialSession(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionStream{Stream: s.openStreamWithID(uint32(streamID))}, nil
+}
+
+// sessionStream wraps a stream and closes the whole session when it gets closed.
+type sessionStream struct {
+	*Stream
+}
+
+func (ss *sessionStream) Close() error {
+	return ss.session.Close()
+}
+
+func readLoop(conn net.Conn, s *Session) {
+	buffer := make([]byte, maxReceivePacketSize)
+	for {
+		n, err := conn.Read(buffer)
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			// A connected udp socket reports icmp errors caused by previously sent packets, which
+			// can be reported before packets that are still queued. The session's fate is decided
+			// by the packets, so the error is ignored.
+			continue
+		}
+		if err != nil {
+			s.closeWithError(err)
+			return
+		}
+		s.handlePacket(buffer[:n])
+	}
 }
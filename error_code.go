@@ -0,0 +1,235 @@
+package quic
+
+import "fmt"
+
+// ErrorCode defines a gQUIC error code (QuicErrorCode). It's sent in connection close and go away
+// frames to explain why a connection has been closed. ErrorCode implements error, so errors.Is can
+// be used to test a ConnectionError for its code.
+type ErrorCode uint32
+
+// Definition of the error codes.
+const (
+	NoError                                 ErrorCode = 0
+	InternalError                           ErrorCode = 1
+	StreamDataAfterTermination              ErrorCode = 2
+	InvalidPacketHeader                     ErrorCode = 3
+	InvalidFrameData                        ErrorCode = 4
+	InvalidFecData                          ErrorCode = 5
+	InvalidRstStreamData                    ErrorCode = 6
+	InvalidConnectionCloseData              ErrorCode = 7
+	InvalidGoawayData                       ErrorCode = 8
+	InvalidAckData                          ErrorCode = 9
+	InvalidVersionNegotiationPacket         ErrorCode = 10
+	InvalidPublicRstPacket                  ErrorCode = 11
+	DecryptionFailure                       ErrorCode = 12
+	EncryptionFailure                       ErrorCode = 13
+	PacketTooLarge                          ErrorCode = 14
+	PeerGoingAway                           ErrorCode = 16
+	InvalidStreamID                         ErrorCode = 17
+	TooManyOpenStreams                      ErrorCode = 18
+	PublicReset                             ErrorCode = 19
+	InvalidVersion                          ErrorCode = 20
+	InvalidHeaderID                         ErrorCode = 22
+	InvalidNegotiatedValue                  ErrorCode = 23
+	DecompressionFailure                    ErrorCode = 24
+	NetworkIdleTimeout                      ErrorCode = 25
+	ErrorMigratingAddress                   ErrorCode = 26
+	PacketWriteError                        ErrorCode = 27
+	HandshakeFailed                         ErrorCode = 28
+	CryptoTagsOutOfOrder                    ErrorCode = 29
+	CryptoTooManyEntries                    ErrorCode = 30
+	CryptoInvalidValueLength                ErrorCode = 31
+	CryptoMessageAfterHandshakeComplete     ErrorCode = 32
+	InvalidCryptoMessageType                ErrorCode = 33
+	InvalidCryptoMessageParameter           ErrorCode = 34
+	CryptoMessageParameterNotFound          ErrorCode = 35
+	CryptoMessageParameterNoOverlap         ErrorCode = 36
+	CryptoMessageIndexNotFound              ErrorCode = 37
+	CryptoInternalError                     ErrorCode = 38
+	CryptoVersionNotSupported               ErrorCode = 39
+	CryptoNoSupport                         ErrorCode = 40
+	CryptoTooManyRejects                    ErrorCode = 41
+	ProofInvalid                            ErrorCode = 42
+	CryptoDuplicateTag                      ErrorCode = 43
+	CryptoEncryptionLevelIncorrect          ErrorCode = 44
+	CryptoServerConfigExpired               ErrorCode = 45
+	InvalidStreamData                       ErrorCode = 46
+	MissingPayload                          ErrorCode = 48
+	InvalidPriority                         ErrorCode = 49
+	EmptyStreamFrameNoFin                   ErrorCode = 50
+	PacketReadError                         ErrorCode = 51
+	InvalidChannelIDSignature               ErrorCode = 52
+	CryptoSymmetricKeySetupFailed           ErrorCode = 53
+	CryptoMessageWhileValidatingClientHello ErrorCode = 54
+	VersionNegotiationMismatch              ErrorCode = 55
+	InvalidHeadersStreamData                ErrorCode = 56
+	InvalidWindowUpdateData                 ErrorCode = 57
+	InvalidBlockedData                      ErrorCode = 58
+	FlowControlReceivedTooMuchData          ErrorCode = 59
+	InvalidStopWaitingData                  ErrorCode = 60
+	UnencryptedStreamData                   ErrorCode = 61
+	ConnectionIPPooled                      ErrorCode = 62
+	FlowControlSentTooMuchData              ErrorCode = 63
+	FlowControlInvalidWindow                ErrorCode = 64
+	CryptoUpdateBeforeHandshakeComplete     ErrorCode = 65
+	HandshakeTimeout                        ErrorCode = 67
+	TooManyOutstandingSentPackets           ErrorCode = 68
+	TooManyOutstandingReceivedPackets       ErrorCode = 69
+	ConnectionCancelled                     ErrorCode = 70
+	BadPacketLossRate                       ErrorCode = 71
+	CryptoHandshakeStatelessReject          ErrorCode = 72
+	PublicResetsPostHandshake               ErrorCode = 73
+	TimeoutsWithOpenStreams                 ErrorCode = 74
+	FailedToSerializePacket                 ErrorCode = 75
+	TooManyAvailableStreams                 ErrorCode = 76
+	UnencryptedFecData                      ErrorCode = 77
+	InvalidPathCloseData                    ErrorCode = 78
+	IPAddressChanged                        ErrorCode = 80
+	ConnectionMigrationNoMigratableStreams  ErrorCode = 81
+	ConnectionMigrationTooManyChanges       ErrorCode = 82
+	ConnectionMigrationNoNewNetwork         ErrorCode = 83
+	ConnectionMigrationNonMigratableStream  ErrorCode = 84
+	TooManyRtos                             ErrorCode = 85
+	ErrorMigratingPort                      ErrorCode = 86
+	OverlappingStreamData                   ErrorCode = 87
+	AttemptToSendUnencryptedStreamData      ErrorCode = 88
+	MaybeCorruptedMemory                    ErrorCode = 89
+	CryptoChloTooLarge                      ErrorCode = 90
+	TooManyFrameGaps                        ErrorCode = 93
+	UnsupportedProofDemand                  ErrorCode = 94
+	StreamSequencerInvalidState             ErrorCode = 95
+	TooManySessionsOnServer                 ErrorCode = 96
+	HeadersStreamDataDecompressFailure      ErrorCode = 97
+)
+
+var errorCodeNames = map[ErrorCode]string{
+	NoError:                                 "QUIC_NO_ERROR",
+	InternalError:                           "QUIC_INTERNAL_ERROR",
+	StreamDataAfterTermination:              "QUIC_STREAM_DATA_AFTER_TERMINATION",
+	InvalidPacketHeader:                     "QUIC_INVALID_PACKET_HEADER",
+	InvalidFrameData:                        "QUIC_INVALID_FRAME_DATA",
+	InvalidFecData:                          "QUIC_INVALID_FEC_DATA",
+	InvalidRstStreamData:                    "QUIC_INVALID_RST_STREAM_DATA",
+	InvalidConnectionCloseData:              "QUIC_INVALID_CONNECTION_CLOSE_DATA",
+	InvalidGoawayData:                       "QUIC_INVALID_GOAWAY_DATA",
+	InvalidAckData:                          "QUIC_INVALID_ACK_DATA",
+	InvalidVersionNegotiationPacket:         "QUIC_INVALID_VERSION_NEGOTIATION_PACKET",
+	InvalidPublicRstPacket:                  "QUIC_INVALID_PUBLIC_RST_PACKET",
+	DecryptionFailure:                       "QUIC_DECRYPTION_FAILURE",
+	EncryptionFailure:                       "QUIC_ENCRYPTION_FAILURE",
+	PacketTooLarge:                          "QUIC_PACKET_TOO_LARGE",
+	PeerGoingAway:                           "QUIC_PEER_GOING_AWAY",
+	InvalidStreamID:                         "QUIC_INVALID_STREAM_ID",
+	TooManyOpenStreams:                      "QUIC_TOO_MANY_OPEN_STREAMS",
+	PublicReset:                             "QUIC_PUBLIC_RESET",
+	InvalidVersion:                          "QUIC_INVALID_VERSION",
+	InvalidHeaderID:                         "QUIC_INVALID_HEADER_ID",
+	InvalidNegotiatedValue:                  "QUIC_INVALID_NEGOTIATED_VALUE",
+	DecompressionFailure:                    "QUIC_DECOMPRESSION_FAILURE",
+	NetworkIdleTimeout:                      "QUIC_NETWORK_IDLE_TIMEOUT",
+	ErrorMigratingAddress:                   "QUIC_ERROR_MIGRATING_ADDRESS",
+	PacketWriteError:                        "QUIC_PACKET_WRITE_ERROR",
+	HandshakeFailed:                         "QUIC_HANDSHAKE_FAILED",
+	CryptoTagsOutOfOrder:                    "QUIC_CRYPTO_TAGS_OUT_OF_ORDER",
+	CryptoTooManyEntries:                    "QUIC_CRYPTO_TOO_MANY_ENTRIES",
+	CryptoInvalidValueLength:                "QUIC_CRYPTO_INVALID_VALUE_LENGTH",
+	CryptoMessageAfterHandshakeComplete:     "QUIC_CRYPTO_MESSAGE_AFTER_HANDSHAKE_COMPLETE",
+	InvalidCryptoMessageType:                "QUIC_INVALID_CRYPTO_MESSAGE_TYPE",
+	InvalidCryptoMessageParameter:           "QUIC_INVALID_CRYPTO_MESSAGE_PARAMETER",
+	CryptoMessageParameterNotFound:          "QUIC_CRYPTO_MESSAGE_PARAMETER_NOT_FOUND",
+	CryptoMessageParameterNoOverlap:         "QUIC_CRYPTO_MESSAGE_PARAMETER_NO_OVERLAP",
+	CryptoMessageIndexNotFound:              "QUIC_CRYPTO_MESSAGE_INDEX_NOT_FOUND",
+	CryptoInternalError:                     "QUIC_CRYPTO_INTERNAL_ERROR",
+	CryptoVersionNotSupported:               "QUIC_CRYPTO_VERSION_NOT_SUPPORTED",
+	CryptoNoSupport:                         "QUIC_CRYPTO_NO_SUPPORT",
+	CryptoTooManyRejects:                    "QUIC_CRYPTO_TOO_MANY_REJECTS",
+	ProofInvalid:                            "QUIC_PROOF_INVALID",
+	CryptoDuplicateTag:                      "QUIC_CRYPTO_DUPLICATE_TAG",
+	CryptoEncryptionLevelIncorrect:          "QUIC_CRYPTO_ENCRYPTION_LEVEL_INCORRECT",
+	CryptoServerConfigExpired:               "QUIC_CRYPTO_SERVER_CONFIG_EXPIRED",
+	InvalidStreamData:                       "QUIC_INVALID_STREAM_DATA",
+	MissingPayload:                          "QUIC_MISSING_PAYLOAD",
+	InvalidPriority:                         "QUIC_INVALID_PRIORITY",
+	EmptyStreamFrameNoFin:                   "QUIC_EMPTY_STREAM_FRAME_NO_FIN",
+	PacketReadError:                         "QUIC_PACKET_READ_ERROR",
+	InvalidChannelIDSignature:               "QUIC_INVALID_CHANNEL_ID_SIGNATURE",
+	CryptoSymmetricKeySetupFailed:           "QUIC_CRYPTO_SYMMETRIC_KEY_SETUP_FAILED",
+	CryptoMessageWhileValidatingClientHello: "QUIC_CRYPTO_MESSAGE_WHILE_VALIDATING_CLIENT_HELLO",
+	VersionNegotiationMismatch:              "QUIC_VERSION_NEGOTIATION_MISMATCH",
+	InvalidHeadersStreamData:                "QUIC_INVALID_HEADERS_STREAM_DATA",
+	InvalidWindowUpdateData:                 "QUIC_INVALID_WINDOW_UPDATE_DATA",
+	InvalidBlockedData:                      "QUIC_INVALID_BLOCKED_DATA",
+	FlowControlReceivedTooMuchData:          "QUIC_FLOW_CONTROL_RECEIVED_TOO_MUCH_DATA",
+	InvalidStopWaitingData:                  "QUIC_INVALID_STOP_WAITING_DATA",
+	UnencryptedStreamData:                   "QUIC_UNENCRYPTED_STREAM_DATA",
+	ConnectionIPPooled:                      "QUIC_CONNECTION_IP_POOLED",
+	FlowControlSentTooMuchData:              "QUIC_FLOW_CONTROL_SENT_TOO_MUCH_DATA",
+	FlowControlInvalidWindow:                "QUIC_FLOW_CONTROL_INVALID_WINDOW",
+	CryptoUpdateBeforeHandshakeComplete:     "QUIC_CRYPTO_UPDATE_BEFORE_HANDSHAKE_COMPLETE",
+	HandshakeTimeout:                        "QUIC_HANDSHAKE_TIMEOUT",
+	TooManyOutstandingSentPackets:           "QUIC_TOO_MANY_OUTSTANDING_SENT_PACKETS",
+	TooManyOutstandingReceivedPackets:       "QUIC_TOO_MANY_OUTSTANDING_RECEIVED_PACKETS",
+	ConnectionCancelled:                     "QUIC_CONNECTION_CANCELLED",
+	BadPacketLossRate:                       "QUIC_BAD_PACKET_LOSS_RATE",
+	CryptoHandshakeStatelessReject:          "QUIC_CRYPTO_HANDSHAKE_STATELESS_REJECT",
+	PublicResetsPostHandshake:               "QUIC_PUBLIC_RESETS_POST_HANDSHAKE",
+	TimeoutsWithOpenStreams:                 "QUIC_TIMEOUTS_WITH_OPEN_STREAMS",
+	FailedToSerializePacket:                 "QUIC_FAILED_TO_SERIALIZE_PACKET",
+	TooManyAvailableStreams:                 "QUIC_TOO_MANY_AVAILABLE_STREAMS",
+	UnencryptedFecData:                      "QUIC_UNENCRYPTED_FEC_DATA",
+	InvalidPathCloseData:                    "QUIC_INVALID_PATH_CLOSE_DATA",
+	IPAddressChanged:                        "QUIC_IP_ADDRESS_CHANGED",
+	ConnectionMigrationNoMigratableStreams:  "QUIC_CONNECTION_MIGRATION_NO_MIGRATABLE_STREAMS",
+	ConnectionMigrationTooManyChanges:       "QUIC_CONNECTION_MIGRATION_TOO_MANY_CHANGES",
+	ConnectionMigrationNoNewNetwork:         "QUIC_CONNECTION_MIGRATION_NO_NEW_NETWORK",
+	ConnectionMigrationNonMigratableStream:  "QUIC_CONNECTION_MIGRATION_NON_MIGRATABLE_STREAM",
+	TooManyRtos:                             "QUIC_TOO_MANY_RTOS",
+	ErrorMigratingPort:                      "QUIC_ERROR_MIGRATING_PORT",
+	OverlappingStreamData:                   "QUIC_OVERLAPPING_STREAM_DATA",
+	AttemptToSendUnencryptedStreamData:      "QUIC_ATTEMPT_TO_SEND_UNENCRYPTED_STREAM_DATA",
+	MaybeCorruptedMemory:                    "QUIC_MAYBE_CORRUPTED_MEMORY",
+	CryptoChloTooLarge:                      "QUIC_CRYPTO_CHLO_TOO_LARGE",
+	TooManyFrameGaps:                        "QUIC_TOO_MANY_FRAME_GAPS",
+	UnsupportedProofDemand:                  "QUIC_UNSUPPORTED_PROOF_DEMAND",
+	StreamSequencerInvalidState:             "QUIC_STREAM_SEQUENCER_INVALID_STATE",
+	TooManySessionsOnServer:                 "QUIC_TOO_MANY_SESSIONS_ON_SERVER",
+	HeadersStreamDataDecompressFailure:      "QUIC_HEADERS_STREAM_DATA_DECOMPRESS_FAILURE",
+}
+
+// String returns the name of the error code as used in the gQUIC specification, e.g.
+// "QUIC_NETWORK_IDLE_TIMEOUT".
+func (ec ErrorCode) String() string {
+	if name, ok := errorCodeNames[ec]; ok {
+		return name
+	}
+	return fmt.Sprintf("QUIC_ERROR_%d", uint32(ec))
+}
+
+// Error returns the error message.
+func (ec ErrorCode) Error() string {
+	return "quic: " + ec.String()
+}
+
+// ConnectionError is returned if the session has been closed with a connection close frame. Remote
+// is true, if the frame has been sent by the peer.
+type ConnectionError struct {
+	ErrorCode    ErrorCode
+	ReasonPhrase string
+	Remote       bool
+}
+
+func (e *ConnectionError) Error() string {
+	side := "local"
+	if e.Remote {
+		side = "remote"
+	}
+	if e.ReasonPhrase == "" {
+		return fmt.Sprintf("quic: connection closed by %s with %s", side, e.ErrorCode.String())
+	}
+	return fmt.Sprintf("quic: connection closed by %s with %s: %s", side, e.ErrorCode.String(), e.ReasonPhrase)
+}
+
+// Unwrap returns the error code.
+func (e *ConnectionError) Unwrap() error {
+	return e.ErrorCode
+}
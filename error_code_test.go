@@ -0,0 +1,42 @@
+package quic_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic"
+)
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		code         quic.ErrorCode
+		expectString string
+	}{
+		{quic.NoError, "QUIC_NO_ERROR"},
+		{quic.InvalidStreamData, "QUIC_INVALID_STREAM_DATA"},
+		{quic.NetworkIdleTimeout, "QUIC_NETWORK_IDLE_TIMEOUT"},
+		{quic.ErrorCode(15), "QUIC_ERROR_15"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expectString, func(t *testing.T) {
+			assert.Equal(t, testCase.expectString, testCase.code.String())
+			assert.Equal(t, "quic: "+testCase.expectString, testCase.code.Error())
+		})
+	}
+}
+
+func TestConnectionError(t *testing.T) {
+	var err error = &quic.ConnectionError{ErrorCode: quic.NetworkIdleTimeout, ReasonPhrase: "idle", Remote: true}
+
+	assert.Equal(t, "quic: connection closed by remote with QUIC_NETWORK_IDLE_TIMEOUT: idle", err.Error())
+	assert.True(t, errors.Is(err, quic.NetworkIdleTimeout))
+	assert.False(t, errors.Is(err, quic.PeerGoingAway))
+
+	var connectionError *quic.ConnectionError
+	if assert.True(t, errors.As(err, &connectionError)) {
+		assert.Equal(t, "idle", connectionError.ReasonPhrase)
+	}
+}
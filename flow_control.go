@@ -0,0 +1,85 @@
+package quic
+
+// Initial flow control windows. Without a handshake that negotiates larger windows, both sides
+// assume the gQUIC default of 16 KiB.
+const (
+	initialStreamWindow     = 16 * 1024
+	initialConnectionWindow = 16 * 1024
+)
+
+// flowController tracks the send and receive window of a stream or of the whole connection. It's
+// not safe for concurrent use, so it has to be guarded by the mutex of its owner.
+type flowController struct {
+	bytesSent     uint64
+	sendWindow    uint64
+	blockedWindow uint64
+
+	windowSize    uint64
+	bytesReceived uint64
+	bytesRead     uint64
+	receiveWindow uint64
+}
+
+func newFlowController(sendWindow, receiveWindow uint64) flowController {
+	return flowController{
+		sendWindow:    sendWindow,
+		windowSize:    receiveWindow,
+		receiveWindow: receiveWindow,
+	}
+}
+
+// sendWindowSize returns the number of bytes that can be sent before the peer's window is
+// exhausted.
+func (fc *flowController) sendWindowSize() uint64 {
+	if fc.bytesSent >= fc.sendWindow {
+		return 0
+	}
+	return fc.sendWindow - fc.bytesSent
+}
+
+func (fc *flowController) addBytesSent(n uint64) {
+	fc.bytesSent += n
+}
+
+// updateSendWindow raises the send window to the provided offset. It returns true, if the window
+// has been raised.
+func (fc *flowController) updateSendWindow(offset uint64) bool {
+	if offset <= fc.sendWindow {
+		return false
+	}
+	fc.sendWindow = offset
+	return true
+}
+
+// shouldSendBlocked returns true, if the send window is exhausted and no blocked frame has been sent
+// for the current window yet.
+func (fc *flowController) shouldSendBlocked() bool {
+	if fc.sendWindowSize() > 0 || fc.blockedWindow == fc.sendWindow {
+		return false
+	}
+	fc.blockedWindow = fc.sendWindow
+	return true
+}
+
+// updateBytesReceived raises the number of received bytes to the provided offset and returns the
+// increase. If the offset exceeds the receive window, false is returned.
+func (fc *flowController) updateBytesReceived(offset uint64) (uint64, bool) {
+	if offset <= fc.bytesReceived {
+		return 0, true
+	}
+	increase := offset - fc.bytesReceived
+	fc.bytesReceived = offset
+	return increase, offset <= fc.receiveWindow
+}
+
+// addBytesRead adds the number of bytes consumed by the application. If the remaining receive
+// window has dropped below half of the window size, the window is raised and the new offset is
+// returned together with true, so a window update can be sent.
+func (fc *flowController) addBytesRead(n uint64) (uint64, bool) {
+	fc.bytesRead += n
+	if fc.receiveWindow-fc.bytesRead > fc.windowSize/2 {
+		return 0, false
+	}
+	fc.receiveWindow = fc.bytesRead + fc.windowSize
+	return fc.receiveWindow, true
+}
@@ -0,0 +1,375 @@
+package frame
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// MaxAckBlocks defines the maximal number of additional ack blocks an acknowledge frame can hold.
+const MaxAckBlocks = 0xff
+
+// AckRange defines a range of acknowledged packet numbers. Both bounds are inclusive.
+type AckRange struct {
+	Smallest uint64
+	Largest  uint64
+}
+
+// AckTimestamp defines the receive time of a packet. The packet is identified by the distance of
+// its packet number to the largest acked one. The time delta of the first timestamp is relative to
+// the receive time of the largest acked packet and the ones of the subsequent timestamps are
+// relative to their predecessor.
+type AckTimestamp struct {
+	DeltaLargestAcked uint8
+	TimeDelta         time.Duration
+}
+
+// Acknowledge defines the acknowledge frame.
+type Acknowledge []byte
+
+// MinAckLen returns the shortest length in bytes (1, 2, 4 or 6) the provided largest acked value or
+// ack block length can be encoded with.
+func MinAckLen(value uint64) int {
+	switch {
+	case value <= 0xff:
+		return 1
+	case value <= 0xffff:
+		return 2
+	case value <= 0xffffffff:
+		return 4
+	}
+	return 6
+}
+
+// ParseAcknowledge validates the acknowledge frame at the beginning of the provided buffer and
+// returns it truncated to the frame's length. If the buffer is too short, ErrTruncated is returned.
+// If the frame isn't an acknowledge frame, ErrInvalidType is returned and if the ack blocks
+// describe packet numbers below zero, ErrInvalidValue is returned. The accessors of the returned
+// frame don't panic.
+func ParseAcknowledge(b []byte) (Acknowledge, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeAcknowledge {
+		return nil, ErrInvalidType
+	}
+
+	a := Acknowledge(b)
+	l := a.numBlocksOffset()
+	if a.multiple() {
+		l++
+	}
+	if len(a) < l {
+		return nil, ErrTruncated
+	}
+	l += a.AckBlockLen() + a.numBlocks()*(1+a.AckBlockLen()) + 1
+	if len(a) < l {
+		return nil, ErrTruncated
+	}
+	l += timestampsLen(int(a[l-1]))
+	if len(a) < l {
+		return nil, ErrTruncated
+	}
+
+	valid := true
+	a.eachAckRange(func(ackRange AckRange, ok bool) {
+		valid = valid && ok
+	})
+	if !valid {
+		return nil, ErrInvalidValue
+	}
+
+	return a[:l], nil
+}
+
+// SetLargestAcked sets the largest acked packet number with the provided length in bytes. The length
+// has to be 1, 2, 4 or 6. Other lengths will cause a panic.
+func (a Acknowledge) SetLargestAcked(value uint64, length int) {
+	flag, ok := ackLenFlag(length)
+	if !ok {
+		panic(fmt.Sprintf("cannot set largest acked with length %d", length))
+	}
+	frameType := Type(a)
+	frameType.SetType(TypeAcknowledge)
+	offset := frameType.Len()
+	a.ensureLen(offset + length)
+
+	frameType.SetFlags(flag << 2)
+	putUint(a[offset:], value, length)
+}
+
+// LargestAcked returns the largest acked packet number.
+func (a Acknowledge) LargestAcked() uint64 {
+	offset := Type(a).Len()
+	a.ensureLen(offset + a.LargestAckedLen())
+	return getUint(a[offset:], a.LargestAckedLen())
+}
+
+// LargestAckedLen returns the length of the largest acked packet number in bytes.
+func (a Acknowledge) LargestAckedLen() int {
+	return ackLen((Type(a).Flags() & MaskLargestAckedLen) >> 2)
+}
+
+// SetAckDelay sets the time that passed between the receipt of the largest acked packet and the
+// sending of the frame.
+func (a Acknowledge) SetAckDelay(delay time.Duration) {
+	offset := a.ackDelayOffset()
+	a.ensureLen(offset + 2)
+	binary.LittleEndian.PutUint16(a[offset:], EncodeUFloat16(uint64(delay/time.Microsecond)))
+}
+
+// AckDelay returns the time that passed between the receipt of the largest acked packet and the
+// sending of the frame.
+func (a Acknowledge) AckDelay() time.Duration {
+	offset := a.ackDelayOffset()
+	a.ensureLen(offset + 2)
+	return time.Duration(DecodeUFloat16(binary.LittleEndian.Uint16(a[offset:]))) * time.Microsecond
+}
+
+// SetAckRanges sets the ack ranges using ack block lengths of the provided length in bytes. The
+// ranges have to be in descending order and the first one has to end with the largest acked packet
+// number. Gaps that are too large for a single ack block are split up. If the ranges need more than
+// MaxAckBlocks additional ack blocks or more than the buffer can hold, the lowest ranges are
+// dropped. The number of written ranges is returned. The timestamps are cleared.
+func (a Acknowledge) SetAckRanges(ranges []AckRange, length int) int {
+	flag, ok := ackLenFlag(length)
+	if !ok {
+		panic(fmt.Sprintf("cannot set ack ranges with block length %d", length))
+	}
+	if len(ranges) == 0 {
+		panic("cannot set empty ack ranges")
+	}
+	frameType := Type(a)
+	frameType.SetFlags(flag)
+
+	maxBlocks := MaxAckBlocks
+	if available := (len(a) - (a.numBlocksOffset() + 1 + length + 1)) / (1 + length); available < maxBlocks {
+		maxBlocks = available
+	}
+
+	numBlocks, numRanges := 0, 1
+	for index := 1; index < len(ranges); index++ {
+		blocks := ackRangeBlocks(ranges[index-1], ranges[index])
+		if numBlocks+blocks > maxBlocks {
+			break
+		}
+		numBlocks += blocks
+		numRanges++
+	}
+
+	offset := a.numBlocksOffset()
+	if numBlocks > 0 {
+		a.ensureLen(offset + 1)
+		frameType.SetFlags(FlagMultiple)
+		a[offset] = uint8(numBlocks)
+		offset++
+	}
+
+	a.ensureLen(offset + length + numBlocks*(1+length) + 1)
+	putUint(a[offset:], a.LargestAcked()-ranges[0].Smallest+1, length)
+	offset += length
+	for index := 1; index < numRanges; index++ {
+		gap := ranges[index-1].Smallest - ranges[index].Largest - 1
+		for ; gap > 0xff; gap -= 0xff {
+			a[offset] = 0xff
+			putUint(a[offset+1:], 0, length)
+			offset += 1 + length
+		}
+		a[offset] = uint8(gap)
+		putUint(a[offset+1:], ranges[index].Largest-ranges[index].Smallest+1, length)
+		offset += 1 + length
+	}
+	a[offset] = 0
+
+	return numRanges
+}
+
+// AckRanges returns the ack ranges in descending order.
+func (a Acknowledge) AckRanges() []AckRange {
+	ranges := []AckRange{}
+	a.eachAckRange(func(ackRange AckRange, ok bool) {
+		ranges = append(ranges, ackRange)
+	})
+	return ranges
+}
+
+// AckBlockLen returns the length of the ack block lengths in bytes.
+func (a Acknowledge) AckBlockLen() int {
+	return ackLen(Type(a).Flags() & MaskAckBlockLen)
+}
+
+// SetTimestamps sets the timestamps. It has to be called after SetAckRanges. The first time delta
+// is encoded with microsecond precision up to about 71 minutes, the subsequent ones in the 16 bit
+// unsigned float format.
+func (a Acknowledge) SetTimestamps(timestamps []AckTimestamp) {
+	if len(timestamps) > 0xff {
+		panic(fmt.Sprintf("cannot set %d timestamps", len(timestamps)))
+	}
+	offset := a.numTimestampsOffset()
+	a.ensureLen(offset + 1 + timestampsLen(len(timestamps)))
+
+	a[offset] = uint8(len(timestamps))
+	offset++
+	for index, timestamp := range timestamps {
+		a[offset] = timestamp.DeltaLargestAcked
+		offset++
+		if index == 0 {
+			binary.LittleEndian.PutUint32(a[offset:], uint32(timestamp.TimeDelta/time.Microsecond))
+			offset += 4
+			continue
+		}
+		binary.LittleEndian.PutUint16(a[offset:], EncodeUFloat16(uint64(timestamp.TimeDelta/time.Microsecond)))
+		offset += 2
+	}
+}
+
+// Timestamps returns the timestamps.
+func (a Acknowledge) Timestamps() []AckTimestamp {
+	offset := a.numTimestampsOffset()
+	a.ensureLen(offset + 1)
+	count := int(a[offset])
+	offset++
+	a.ensureLen(offset + timestampsLen(count))
+
+	timestamps := make([]AckTimestamp, count)
+	for index := range timestamps {
+		timestamps[index].DeltaLargestAcked = a[offset]
+		offset++
+		if index == 0 {
+			timestamps[index].TimeDelta = time.Duration(binary.LittleEndian.Uint32(a[offset:])) * time.Microsecond
+			offset += 4
+			continue
+		}
+		timestamps[index].TimeDelta = time.Duration(DecodeUFloat16(binary.LittleEndian.Uint16(a[offset:]))) * time.Microsecond
+		offset += 2
+	}
+	return timestamps
+}
+
+// Len returns the length of the acknowledge frame.
+func (a Acknowledge) Len() int {
+	offset := a.numTimestampsOffset()
+	a.ensureLen(offset + 1)
+	return offset + 1 + timestampsLen(int(a[offset]))
+}
+
+// eachAckRange calls the provided function for each ack range. If the ack blocks describe packet
+// numbers below zero, the function is called with ok set to false and the iteration stops.
+func (a Acknowledge) eachAckRange(fn func(AckRange, bool)) {
+	largest := a.LargestAcked()
+	length := a.AckBlockLen()
+	offset := a.numBlocksOffset()
+	if a.multiple() {
+		offset++
+	}
+
+	firstLen := getUint(a[offset:], length)
+	offset += length
+	if firstLen == 0 || firstLen > largest+1 {
+		fn(AckRange{}, false)
+		return
+	}
+	smallest := largest - firstLen + 1
+	fn(AckRange{Smallest: smallest, Largest: largest}, true)
+
+	for index := 0; index < a.numBlocks(); index++ {
+		gap := uint64(a[offset])
+		blockLen := getUint(a[offset+1:], length)
+		offset += 1 + length
+
+		if blockLen == 0 {
+			if gap > smallest {
+				fn(AckRange{}, false)
+				return
+			}
+			smallest -= gap
+			continue
+		}
+		if gap+1 > smallest || blockLen > smallest-gap {
+			fn(AckRange{}, false)
+			return
+		}
+		largest = smallest - gap - 1
+		smallest = largest - blockLen + 1
+		fn(AckRange{Smallest: smallest, Largest: largest}, true)
+	}
+}
+
+func (a Acknowledge) ensureLen(l int) {
+	if len(a) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(a)))
+	}
+}
+
+func (a Acknowledge) multiple() bool {
+	return Type(a).Flags()&FlagMultiple != 0x00
+}
+
+func (a Acknowledge) ackDelayOffset() int {
+	return Type(a).Len() + a.LargestAckedLen()
+}
+
+func (a Acknowledge) numBlocksOffset() int {
+	return a.ackDelayOffset() + 2
+}
+
+func (a Acknowledge) numBlocks() int {
+	if !a.multiple() {
+		return 0
+	}
+	offset := a.numBlocksOffset()
+	a.ensureLen(offset + 1)
+	return int(a[offset])
+}
+
+func (a Acknowledge) numTimestampsOffset() int {
+	offset := a.numBlocksOffset()
+	if a.multiple() {
+		offset++
+	}
+	return offset + a.AckBlockLen() + a.numBlocks()*(1+a.AckBlockLen())
+}
+
+// ackRangeBlocks returns the number of ack blocks that are needed to encode the range following the
+// previous one.
+func ackRangeBlocks(previous, current AckRange) int {
+	gap := previous.Smallest - current.Largest - 1
+	if gap <= 0xff {
+		return 1
+	}
+	return 1 + int((gap-1)/0xff)
+}
+
+func timestampsLen(count int) int {
+	if count == 0 {
+		return 0
+	}
+	return 1 + 4 + (count-1)*(1+2)
+}
+
+func ackLen(flag uint8) int {
+	switch flag {
+	case 0x03:
+		return 6
+	case 0x02:
+		return 4
+	case 0x01:
+		return 2
+	}
+	return 1
+}
+
+func ackLenFlag(length int) (uint8, bool) {
+	switch length {
+	case 6:
+		return 0x03, true
+	case 4:
+		return 0x02, true
+	case 2:
+		return 0x01, true
+	case 1:
+		return 0x00, true
+	}
+	return 0, false
+}
@@ -0,0 +1,136 @@
+package frame_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestAcknowledge(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		largestAcked    uint64
+		largestAckedLen int
+		ackDelay        time.Duration
+		ackRanges       []frame.AckRange
+		ackBlockLen     int
+		timestamps      []frame.AckTimestamp
+
+		bytes []byte
+	}{
+		{"Single", 5, 1, 0, []frame.AckRange{{1, 5}}, 1, nil,
+			[]byte{0x40, 0x05, 0x00, 0x00, 0x05, 0x00}},
+		{"Multiple", 0x0102, 2, 4096 * time.Microsecond, []frame.AckRange{{0x100, 0x102}, {0xf0, 0xfa}}, 1, nil,
+			[]byte{0x64, 0x02, 0x01, 0x00, 0x10, 0x01, 0x03, 0x05, 0x0b, 0x00}},
+		{"LargeGap", 1000, 2, 0, []frame.AckRange{{1000, 1000}, {100, 100}}, 1, nil,
+			[]byte{0x64, 0xe8, 0x03, 0x00, 0x00, 0x04, 0x01, 0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0x86, 0x01, 0x00}},
+		{"Timestamps", 5, 1, 0, []frame.AckRange{{1, 5}}, 1,
+			[]frame.AckTimestamp{{0, 0x01020304 * time.Microsecond}, {1, 100 * time.Microsecond}},
+			[]byte{0x40, 0x05, 0x00, 0x00, 0x05, 0x02, 0x00, 0x04, 0x03, 0x02, 0x01, 0x01, 0x64, 0x00}},
+		{"Length6", 0x010000000000, 6, 0, []frame.AckRange{{1, 0x010000000000}}, 6, nil,
+			[]byte{0x4f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				ack := frame.Acknowledge(buffer)
+				ack.SetLargestAcked(testCase.largestAcked, testCase.largestAckedLen)
+				ack.SetAckDelay(testCase.ackDelay)
+				assert.Equal(t, len(testCase.ackRanges), ack.SetAckRanges(testCase.ackRanges, testCase.ackBlockLen))
+				if testCase.timestamps != nil {
+					ack.SetTimestamps(testCase.timestamps)
+				}
+
+				assert.Equal(t, len(testCase.bytes), ack.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				ack := frame.Acknowledge(testCase.bytes)
+				assert.Equal(t, testCase.largestAcked, ack.LargestAcked())
+				assert.Equal(t, testCase.largestAckedLen, ack.LargestAckedLen())
+				assert.Equal(t, testCase.ackDelay, ack.AckDelay())
+				assert.Equal(t, testCase.ackRanges, ack.AckRanges())
+				assert.Equal(t, testCase.ackBlockLen, ack.AckBlockLen())
+				if testCase.timestamps != nil {
+					assert.Equal(t, testCase.timestamps, ack.Timestamps())
+				} else {
+					assert.Empty(t, ack.Timestamps())
+				}
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				ack, err := frame.ParseAcknowledge(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				assert.Equal(t, len(testCase.bytes), len(ack))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseAcknowledge(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestAcknowledgeMaxAckBlocks(t *testing.T) {
+	ranges := []frame.AckRange{}
+	for index := uint64(0); index < 300; index++ {
+		packetNumber := 1000 - 2*index
+		ranges = append(ranges, frame.AckRange{Smallest: packetNumber, Largest: packetNumber})
+	}
+
+	ack := frame.Acknowledge(make([]byte, 1000))
+	ack.SetLargestAcked(1000, 2)
+	assert.Equal(t, frame.MaxAckBlocks+1, ack.SetAckRanges(ranges, 1))
+	assert.Equal(t, ranges[:frame.MaxAckBlocks+1], ack.AckRanges())
+}
+
+func TestAcknowledgeBufferLimit(t *testing.T) {
+	ranges := []frame.AckRange{{10, 10}, {8, 8}, {6, 6}, {4, 4}}
+
+	buffer := make([]byte, 1+1+2+1+1+2*(1+1)+1)
+	ack := frame.Acknowledge(buffer)
+	ack.SetLargestAcked(10, 1)
+	assert.Equal(t, 3, ack.SetAckRanges(ranges, 1))
+	assert.Equal(t, len(buffer), ack.Len())
+	assert.Equal(t, ranges[:3], ack.AckRanges())
+}
+
+func TestParseAcknowledgeErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		bytes []byte
+		err   error
+	}{
+		{"Empty", []byte{}, frame.ErrTruncated},
+		{"Stream", []byte{0x80, 0x01}, frame.ErrInvalidType},
+		{"FirstBlockTooLong", []byte{0x40, 0x05, 0x00, 0x00, 0x07, 0x00}, frame.ErrInvalidValue},
+		{"FirstBlockEmpty", []byte{0x40, 0x05, 0x00, 0x00, 0x00, 0x00}, frame.ErrInvalidValue},
+		{"GapTooLarge", []byte{0x60, 0x05, 0x00, 0x00, 0x01, 0x02, 0x06, 0x01, 0x00}, frame.ErrInvalidValue},
+		{"BlockTooLong", []byte{0x60, 0x05, 0x00, 0x00, 0x01, 0x02, 0x01, 0x04, 0x00}, frame.ErrInvalidValue},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := frame.ParseAcknowledge(testCase.bytes)
+			assert.Equal(t, testCase.err, err)
+		})
+	}
+}
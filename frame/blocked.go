@@ -0,0 +1,58 @@
+package frame
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Blocked defines the blocked frame. It signals that the sender has data to send on a stream or,
+// with stream id zero, on the connection, but is blocked by flow control.
+type Blocked []byte
+
+// blockedLen defines the length of a blocked frame.
+const blockedLen = 1 + 4
+
+// ParseBlocked validates the blocked frame at the beginning of the provided buffer and returns it
+// truncated to the frame's length. If the buffer is too short, ErrTruncated is returned. If the
+// frame isn't a blocked frame, ErrInvalidType is returned. The accessors of the returned frame don't
+// panic.
+func ParseBlocked(b []byte) (Blocked, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeBlocked {
+		return nil, ErrInvalidType
+	}
+	if len(b) < blockedLen {
+		return nil, ErrTruncated
+	}
+	return Blocked(b[:blockedLen]), nil
+}
+
+// SetStreamID sets the stream id. Zero refers to the connection.
+func (bl Blocked) SetStreamID(value uint32) {
+	frameType := Type(bl)
+	frameType.SetType(TypeBlocked)
+	offset := frameType.Len()
+	bl.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(bl[offset:], value)
+}
+
+// StreamID returns the stream id.
+func (bl Blocked) StreamID() uint32 {
+	offset := Type(bl).Len()
+	bl.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(bl[offset:])
+}
+
+// Len returns the length of the blocked frame.
+func (bl Blocked) Len() int {
+	return blockedLen
+}
+
+func (bl Blocked) ensureLen(l int) {
+	if len(bl) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(bl)))
+	}
+}
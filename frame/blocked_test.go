@@ -0,0 +1,66 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestBlocked(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		streamID uint32
+
+		bytes []byte
+	}{
+		{"Stream", 0x04030201, []byte{0x05, 0x01, 0x02, 0x03, 0x04}},
+		{"Connection", 0, []byte{0x05, 0x00, 0x00, 0x00, 0x00}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				blocked := frame.Blocked(buffer)
+				blocked.SetStreamID(testCase.streamID)
+
+				assert.Equal(t, len(testCase.bytes), blocked.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				blocked := frame.Blocked(testCase.bytes)
+				assert.Equal(t, testCase.streamID, blocked.StreamID())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				blocked, err := frame.ParseBlocked(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				assert.Equal(t, testCase.bytes, []byte(blocked))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseBlocked(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseBlockedErrors(t *testing.T) {
+	_, err := frame.ParseBlocked([]byte{0x04})
+	assert.Equal(t, frame.ErrInvalidType, err)
+}
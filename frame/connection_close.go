@@ -0,0 +1,93 @@
+package frame
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// MaxReasonPhraseLen defines the maximal length of a reason phrase.
+const MaxReasonPhraseLen = 0xffff
+
+// ConnectionClose defines the connection close frame. It closes the connection with an error code
+// and a human readable reason phrase.
+type ConnectionClose []byte
+
+// ParseConnectionClose validates the connection close frame at the beginning of the provided buffer
+// and returns it truncated to the frame's length. If the buffer is too short, ErrTruncated is
+// returned. If the frame isn't a connection close frame, ErrInvalidType is returned. The accessors
+// of the returned frame don't panic.
+func ParseConnectionClose(b []byte) (ConnectionClose, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeConnectionClose {
+		return nil, ErrInvalidType
+	}
+
+	cc := ConnectionClose(b)
+	offset := cc.reasonPhraseLenOffset()
+	if len(cc) < offset+2 {
+		return nil, ErrTruncated
+	}
+	l := offset + 2 + int(binary.LittleEndian.Uint16(cc[offset:]))
+	if len(cc) < l {
+		return nil, ErrTruncated
+	}
+	return cc[:l], nil
+}
+
+// SetErrorCode sets the error code.
+func (cc ConnectionClose) SetErrorCode(value uint32) {
+	frameType := Type(cc)
+	frameType.SetType(TypeConnectionClose)
+	offset := frameType.Len()
+	cc.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(cc[offset:], value)
+}
+
+// ErrorCode returns the error code.
+func (cc ConnectionClose) ErrorCode() uint32 {
+	offset := Type(cc).Len()
+	cc.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(cc[offset:])
+}
+
+// SetReasonPhrase sets the reason phrase preceded by its length. A reason phrase longer than
+// MaxReasonPhraseLen will cause a panic.
+func (cc ConnectionClose) SetReasonPhrase(value string) {
+	if len(value) > MaxReasonPhraseLen {
+		panic(fmt.Sprintf("cannot set reason phrase with length %d", len(value)))
+	}
+	offset := cc.reasonPhraseLenOffset()
+	cc.ensureLen(offset + 2 + len(value))
+	binary.LittleEndian.PutUint16(cc[offset:], uint16(len(value)))
+	copy(cc[offset+2:], value)
+}
+
+// ReasonPhrase returns the reason phrase.
+func (cc ConnectionClose) ReasonPhrase() string {
+	offset := cc.reasonPhraseLenOffset()
+	cc.ensureLen(offset + 2)
+	l := int(binary.LittleEndian.Uint16(cc[offset:]))
+	offset += 2
+	cc.ensureLen(offset + l)
+	return string(cc[offset : offset+l])
+}
+
+// Len returns the length of the connection close frame.
+func (cc ConnectionClose) Len() int {
+	offset := cc.reasonPhraseLenOffset()
+	cc.ensureLen(offset + 2)
+	return offset + 2 + int(binary.LittleEndian.Uint16(cc[offset:]))
+}
+
+func (cc ConnectionClose) ensureLen(l int) {
+	if len(cc) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(cc)))
+	}
+}
+
+func (cc ConnectionClose) reasonPhraseLenOffset() int {
+	return Type(cc).Len() + 4
+}
@@ -0,0 +1,71 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestConnectionClose(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		errorCode    uint32
+		reasonPhrase string
+
+		bytes []byte
+	}{
+		{"Regular", 25, "idle",
+			[]byte{0x02, 0x19, 0x00, 0x00, 0x00, 0x04, 0x00, 0x69, 0x64, 0x6c, 0x65}},
+		{"NoReasonPhrase", 0, "",
+			[]byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				connectionClose := frame.ConnectionClose(buffer)
+				connectionClose.SetErrorCode(testCase.errorCode)
+				connectionClose.SetReasonPhrase(testCase.reasonPhrase)
+
+				assert.Equal(t, len(testCase.bytes), connectionClose.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				connectionClose := frame.ConnectionClose(testCase.bytes)
+				assert.Equal(t, testCase.errorCode, connectionClose.ErrorCode())
+				assert.Equal(t, testCase.reasonPhrase, connectionClose.ReasonPhrase())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				connectionClose, err := frame.ParseConnectionClose(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				assert.Equal(t, testCase.bytes, []byte(connectionClose))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseConnectionClose(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseConnectionCloseErrors(t *testing.T) {
+	_, err := frame.ParseConnectionClose([]byte{0x01})
+	assert.Equal(t, frame.ErrInvalidType, err)
+}
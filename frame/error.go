@@ -0,0 +1,10 @@
+package frame
+
+import "errors"
+
+// Errors returned by the parse functions.
+var (
+	ErrTruncated    = errors.New("frame: truncated")
+	ErrInvalidType  = errors.New("frame: invalid type")
+	ErrInvalidValue = errors.New("frame: invalid value")
+)
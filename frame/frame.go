@@ -0,0 +1,63 @@
+package frame
+
+// Frame defines a parsed frame view. The concrete types are Stream, Acknowledge, Padding,
+// ResetStream, ConnectionClose, GoAway, WindowUpdate, Blocked, StopWaiting and Ping.
+type Frame interface {
+	Len() int
+}
+
+var (
+	_ Frame = Stream(nil)
+	_ Frame = Acknowledge(nil)
+	_ Frame = Padding(nil)
+	_ Frame = ResetStream(nil)
+	_ Frame = ConnectionClose(nil)
+	_ Frame = GoAway(nil)
+	_ Frame = WindowUpdate(nil)
+	_ Frame = Blocked(nil)
+	_ Frame = StopWaiting(nil)
+	_ Frame = Ping(nil)
+)
+
+// Parse validates the frame at the beginning of the provided buffer and returns the typed frame
+// view truncated to the frame's length. Stream frames without data length field and padding frames
+// extend to the end of the buffer, so the buffer has to end with the enclosing packet's payload.
+// The packet number length of the enclosing packet is needed for stop waiting frames. If the frame
+// type is unknown, ErrInvalidType is returned. Otherwise, the errors of the type's parse function
+// are returned.
+func Parse(b []byte, packetNumberLen int) (Frame, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var f Frame
+	switch frameType.Type() {
+	case TypeStream:
+		f, err = ParseStream(b)
+	case TypeAcknowledge:
+		f, err = ParseAcknowledge(b)
+	case TypePadding:
+		f, err = ParsePadding(b)
+	case TypeResetStream:
+		f, err = ParseResetStream(b)
+	case TypeConnectionClose:
+		f, err = ParseConnectionClose(b)
+	case TypeGoAway:
+		f, err = ParseGoAway(b)
+	case TypeWindowUpdate:
+		f, err = ParseWindowUpdate(b)
+	case TypeBlocked:
+		f, err = ParseBlocked(b)
+	case TypeStopWaiting:
+		f, err = ParseStopWaiting(b, packetNumberLen)
+	case TypePing:
+		f, err = ParsePing(b)
+	default:
+		err = ErrInvalidType
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,58 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name        string
+		bytes       []byte
+		expectFrame frame.Frame
+		expectErr   error
+	}{
+		{"Stream", []byte{0xa0, 0x01, 0x01, 0x00, 0x03, 0xff},
+			frame.Stream{0xa0, 0x01, 0x01, 0x00, 0x03}, nil},
+		{"ImplicitStream", []byte{0x80, 0x01, 0x03, 0x04},
+			frame.Stream{0x80, 0x01, 0x03, 0x04}, nil},
+		{"Acknowledge", []byte{0x40, 0x01, 0x00, 0x00, 0x01, 0x00, 0xff},
+			frame.Acknowledge{0x40, 0x01, 0x00, 0x00, 0x01, 0x00}, nil},
+		{"Padding", []byte{0x00, 0x00, 0x00},
+			frame.Padding{0x00, 0x00, 0x00}, nil},
+		{"ResetStream", []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00},
+			frame.ResetStream{0x01, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00}, nil},
+		{"ConnectionClose", []byte{0x02, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff},
+			frame.ConnectionClose{0x02, 0x19, 0x00, 0x00, 0x00, 0x00, 0x00}, nil},
+		{"GoAway", []byte{0x03, 0x10, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00},
+			frame.GoAway{0x03, 0x10, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00}, nil},
+		{"WindowUpdate", []byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			frame.WindowUpdate{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, nil},
+		{"Blocked", []byte{0x05, 0x03, 0x00, 0x00, 0x00},
+			frame.Blocked{0x05, 0x03, 0x00, 0x00, 0x00}, nil},
+		{"StopWaiting", []byte{0x06, 0x01, 0x02, 0xff},
+			frame.StopWaiting{0x06, 0x01, 0x02}, nil},
+		{"Ping", []byte{0x07, 0xff},
+			frame.Ping{0x07}, nil},
+		{"Empty", []byte{}, nil, frame.ErrTruncated},
+		{"UnknownType", []byte{0x08}, nil, frame.ErrInvalidType},
+		{"TruncatedStream", []byte{0xa0, 0x01, 0x01, 0x00}, nil, frame.ErrTruncated},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			f, err := frame.Parse(testCase.bytes, 2)
+			if testCase.expectErr != nil {
+				assert.Equal(t, testCase.expectErr, err)
+				assert.Nil(t, f)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectFrame, f)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package frame
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GoAway defines the go away frame. It announces that the connection will be closed. Streams up to
+// the last good stream id are processed, new streams are rejected.
+type GoAway []byte
+
+// ParseGoAway validates the go away frame at the beginning of the provided buffer and returns it
+// truncated to the frame's length. If the buffer is too short, ErrTruncated is returned. If the
+// frame isn't a go away frame, ErrInvalidType is returned. The accessors of the returned frame
+// don't panic.
+func ParseGoAway(b []byte) (GoAway, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeGoAway {
+		return nil, ErrInvalidType
+	}
+
+	ga := GoAway(b)
+	offset := ga.reasonPhraseLenOffset()
+	if len(ga) < offset+2 {
+		return nil, ErrTruncated
+	}
+	l := offset + 2 + int(binary.LittleEndian.Uint16(ga[offset:]))
+	if len(ga) < l {
+		return nil, ErrTruncated
+	}
+	return ga[:l], nil
+}
+
+// SetErrorCode sets the error code.
+func (ga GoAway) SetErrorCode(value uint32) {
+	frameType := Type(ga)
+	frameType.SetType(TypeGoAway)
+	offset := frameType.Len()
+	ga.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(ga[offset:], value)
+}
+
+// ErrorCode returns the error code.
+func (ga GoAway) ErrorCode() uint32 {
+	offset := Type(ga).Len()
+	ga.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(ga[offset:])
+}
+
+// SetLastGoodStreamID sets the id of the last stream that has been accepted by the sender.
+func (ga GoAway) SetLastGoodStreamID(value uint32) {
+	offset := Type(ga).Len() + 4
+	ga.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(ga[offset:], value)
+}
+
+// LastGoodStreamID returns the id of the last stream that has been accepted by the sender.
+func (ga GoAway) LastGoodStreamID() uint32 {
+	offset := Type(ga).Len() + 4
+	ga.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(ga[offset:])
+}
+
+// SetReasonPhrase sets the reason phrase preceded by its length. A reason phrase longer than
+// MaxReasonPhraseLen will cause a panic.
+func (ga GoAway) SetReasonPhrase(value string) {
+	if len(value) > MaxReasonPhraseLen {
+		panic(fmt.Sprintf("cannot set reason phrase with length %d", len(value)))
+	}
+	offset := ga.reasonPhraseLenOffset()
+	ga.ensureLen(offset + 2 + len(value))
+	binary.LittleEndian.PutUint16(ga[offset:], uint16(len(value)))
+	copy(ga[offset+2:], value)
+}
+
+// ReasonPhrase returns the reason phrase.
+func (ga GoAway) ReasonPhrase() string {
+	offset := ga.reasonPhraseLenOffset()
+	ga.ensureLen(offset + 2)
+	l := int(binary.LittleEndian.Uint16(ga[offset:]))
+	offset += 2
+	ga.ensureLen(offset + l)
+	return string(ga[offset : offset+l])
+}
+
+// Len returns the length of the go away frame.
+func (ga GoAway) Len() int {
+	offset := ga.reasonPhraseLenOffset()
+	ga.ensureLen(offset + 2)
+	return offset + 2 + int(binary.LittleEndian.Uint16(ga[offset:]))
+}
+
+func (ga GoAway) ensureLen(l int) {
+	if len(ga) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(ga)))
+	}
+}
+
+func (ga GoAway) reasonPhraseLenOffset() int {
+	return Type(ga).Len() + 4 + 4
+}
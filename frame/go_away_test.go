@@ -0,0 +1,74 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestGoAway(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		errorCode        uint32
+		lastGoodStreamID uint32
+		reasonPhrase     string
+
+		bytes []byte
+	}{
+		{"Regular", 16, 0x0105, "bye",
+			[]byte{0x03, 0x10, 0x00, 0x00, 0x00, 0x05, 0x01, 0x00, 0x00, 0x03, 0x00, 0x62, 0x79, 0x65}},
+		{"NoReasonPhrase", 0, 0, "",
+			[]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				goAway := frame.GoAway(buffer)
+				goAway.SetErrorCode(testCase.errorCode)
+				goAway.SetLastGoodStreamID(testCase.lastGoodStreamID)
+				goAway.SetReasonPhrase(testCase.reasonPhrase)
+
+				assert.Equal(t, len(testCase.bytes), goAway.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				goAway := frame.GoAway(testCase.bytes)
+				assert.Equal(t, testCase.errorCode, goAway.ErrorCode())
+				assert.Equal(t, testCase.lastGoodStreamID, goAway.LastGoodStreamID())
+				assert.Equal(t, testCase.reasonPhrase, goAway.ReasonPhrase())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				goAway, err := frame.ParseGoAway(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				assert.Equal(t, testCase.bytes, []byte(goAway))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseGoAway(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseGoAwayErrors(t *testing.T) {
+	_, err := frame.ParseGoAway([]byte{0x02})
+	assert.Equal(t, frame.ErrInvalidType, err)
+}
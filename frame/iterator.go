@@ -0,0 +1,63 @@
+package frame
+
+// Iterator walks the frames of a packet's payload.
+//
+//	it := frame.NewIterator(regular.Data(), regular.PacketNumberLen())
+//	for it.Next() {
+//		switch f := it.Frame().(type) {
+//		case frame.Stream:
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	data            []byte
+	packetNumberLen int
+	frameType       uint8
+	frame           Frame
+	err             error
+}
+
+// NewIterator returns an iterator over the frames in the provided payload. The packet number length
+// of the enclosing packet is needed to parse stop waiting frames.
+func NewIterator(data []byte, packetNumberLen int) *Iterator {
+	return &Iterator{data: data, packetNumberLen: packetNumberLen}
+}
+
+// Next advances to the next frame. It returns false at the end of the payload or if a frame can't
+// be parsed.
+func (it *Iterator) Next() bool {
+	it.frame = nil
+	if it.err != nil || len(it.data) == 0 {
+		return false
+	}
+
+	it.frameType = Type(it.data).Type()
+	f, err := Parse(it.data, it.packetNumberLen)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.frame = f
+	it.data = it.data[f.Len():]
+	return true
+}
+
+// Frame returns the current frame.
+func (it *Iterator) Frame() Frame {
+	return it.frame
+}
+
+// Type returns the type of the current frame. After a failed Next, it returns the type of the frame
+// that couldn't be parsed.
+func (it *Iterator) Type() uint8 {
+	return it.frameType
+}
+
+// Err returns the error that stopped the iteration or nil, if the end of the payload has been
+// reached.
+func (it *Iterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,61 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestIterator(t *testing.T) {
+	testCases := []struct {
+		name            string
+		packetNumberLen int
+		bytes           []byte
+		expectFrames    []frame.Frame
+		expectType      uint8
+		expectErr       error
+	}{
+		{"Empty", 1, []byte{}, []frame.Frame{}, 0, nil},
+		{"AcknowledgeAndImplicitStream", 1,
+			[]byte{0x40, 0x01, 0x00, 0x00, 0x01, 0x00, 0x80, 0x01, 0x03, 0x04},
+			[]frame.Frame{
+				frame.Acknowledge{0x40, 0x01, 0x00, 0x00, 0x01, 0x00},
+				frame.Stream{0x80, 0x01, 0x03, 0x04},
+			}, frame.TypeStream, nil},
+		{"StopWaitingAndStreamAndPadding", 1,
+			[]byte{0x06, 0x01, 0xa0, 0x01, 0x01, 0x00, 0x03, 0x00, 0x00, 0x00},
+			[]frame.Frame{
+				frame.StopWaiting{0x06, 0x01},
+				frame.Stream{0xa0, 0x01, 0x01, 0x00, 0x03},
+				frame.Padding{0x00, 0x00, 0x00},
+			}, frame.TypePadding, nil},
+		{"StopWaitingLen4AndPing", 4,
+			[]byte{0x06, 0x01, 0x02, 0x03, 0x04, 0x07},
+			[]frame.Frame{
+				frame.StopWaiting{0x06, 0x01, 0x02, 0x03, 0x04},
+				frame.Ping{0x07},
+			}, frame.TypePing, nil},
+		{"PingAndUnknownType", 1,
+			[]byte{0x07, 0x08},
+			[]frame.Frame{frame.Ping{0x07}}, 0x08, frame.ErrInvalidType},
+		{"TruncatedStream", 1,
+			[]byte{0x07, 0xa0, 0x01, 0x01, 0x00},
+			[]frame.Frame{frame.Ping{0x07}}, frame.TypeStream, frame.ErrTruncated},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			frames := []frame.Frame{}
+			it := frame.NewIterator(testCase.bytes, testCase.packetNumberLen)
+			for it.Next() {
+				frames = append(frames, it.Frame())
+			}
+			assert.Equal(t, testCase.expectFrames, frames)
+			assert.Equal(t, testCase.expectType, it.Type())
+			assert.Equal(t, testCase.expectErr, it.Err())
+			assert.Nil(t, it.Frame())
+		})
+	}
+}
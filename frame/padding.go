@@ -0,0 +1,41 @@
+package frame
+
+import "fmt"
+
+// Padding defines the padding frame. It extends to the end of the packet, so it has to be the last
+// frame in the packet.
+type Padding []byte
+
+// ParsePadding validates the padding frame at the beginning of the provided buffer and returns it.
+// Since the frame extends to the end of the packet, the buffer has to end with the enclosing
+// packet's payload. If the buffer is empty, ErrTruncated is returned. If the frame isn't a padding
+// frame, ErrInvalidType is returned.
+func ParsePadding(b []byte) (Padding, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypePadding {
+		return nil, ErrInvalidType
+	}
+	return Padding(b), nil
+}
+
+// Set writes the padding frame, which fills the whole buffer with zeros.
+func (p Padding) Set() {
+	p.ensureLen(1)
+	for index := range p {
+		p[index] = TypePadding
+	}
+}
+
+// Len returns the length of the padding frame, which is bounded by the end of the buffer.
+func (p Padding) Len() int {
+	return len(p)
+}
+
+func (p Padding) ensureLen(l int) {
+	if len(p) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(p)))
+	}
+}
@@ -0,0 +1,33 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestPadding(t *testing.T) {
+	t.Run("Write", func(t *testing.T) {
+		buffer := []byte{0xff, 0xff, 0xff}
+
+		padding := frame.Padding(buffer)
+		padding.Set()
+
+		assert.Equal(t, 3, padding.Len())
+		assert.Equal(t, []byte{0x00, 0x00, 0x00}, buffer)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		padding, err := frame.ParsePadding([]byte{0x00, 0x00, 0x00, 0x00})
+		require.NoError(t, err)
+		assert.Equal(t, 4, padding.Len())
+
+		_, err = frame.ParsePadding([]byte{})
+		assert.Equal(t, frame.ErrTruncated, err)
+		_, err = frame.ParsePadding([]byte{0x07})
+		assert.Equal(t, frame.ErrInvalidType, err)
+	})
+}
@@ -0,0 +1,38 @@
+package frame
+
+import "fmt"
+
+// Ping defines the ping frame. It carries no data, but has to be acknowledged by the peer, so it
+// can be used to keep a connection alive.
+type Ping []byte
+
+// ParsePing validates the ping frame at the beginning of the provided buffer and returns it
+// truncated to the frame's length. If the buffer is empty, ErrTruncated is returned. If the frame
+// isn't a ping frame, ErrInvalidType is returned.
+func ParsePing(b []byte) (Ping, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypePing {
+		return nil, ErrInvalidType
+	}
+	return Ping(b[:frameType.Len()]), nil
+}
+
+// Set writes the ping frame.
+func (p Ping) Set() {
+	p.ensureLen(1)
+	Type(p).SetType(TypePing)
+}
+
+// Len returns the length of the ping frame.
+func (p Ping) Len() int {
+	return Type(p).Len()
+}
+
+func (p Ping) ensureLen(l int) {
+	if len(p) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(p)))
+	}
+}
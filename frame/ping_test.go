@@ -0,0 +1,33 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestPing(t *testing.T) {
+	t.Run("Write", func(t *testing.T) {
+		buffer := make([]byte, 1)
+
+		ping := frame.Ping(buffer)
+		ping.Set()
+
+		assert.Equal(t, 1, ping.Len())
+		assert.Equal(t, []byte{0x07}, buffer)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		ping, err := frame.ParsePing([]byte{0x07, 0xff})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x07}, []byte(ping))
+
+		_, err = frame.ParsePing([]byte{})
+		assert.Equal(t, frame.ErrTruncated, err)
+		_, err = frame.ParsePing([]byte{0x00})
+		assert.Equal(t, frame.ErrInvalidType, err)
+	})
+}
@@ -0,0 +1,86 @@
+package frame
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ResetStream defines the reset stream frame. It aborts the sending side of a stream.
+type ResetStream []byte
+
+// resetStreamLen defines the length of a reset stream frame.
+const resetStreamLen = 1 + 4 + 8 + 4
+
+// ParseResetStream validates the reset stream frame at the beginning of the provided buffer and
+// returns it truncated to the frame's length. If the buffer is too short, ErrTruncated is returned.
+// If the frame isn't a reset stream frame, ErrInvalidType is returned. The accessors of the
+// returned frame don't panic.
+func ParseResetStream(b []byte) (ResetStream, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeResetStream {
+		return nil, ErrInvalidType
+	}
+	if len(b) < resetStreamLen {
+		return nil, ErrTruncated
+	}
+	return ResetStream(b[:resetStreamLen]), nil
+}
+
+// SetStreamID sets the stream id.
+func (rs ResetStream) SetStreamID(value uint32) {
+	frameType := Type(rs)
+	frameType.SetType(TypeResetStream)
+	offset := frameType.Len()
+	rs.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(rs[offset:], value)
+}
+
+// StreamID returns the stream id.
+func (rs ResetStream) StreamID() uint32 {
+	offset := Type(rs).Len()
+	rs.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(rs[offset:])
+}
+
+// SetByteOffset sets the final byte offset of the stream, which is the number of bytes that have
+// been sent on it.
+func (rs ResetStream) SetByteOffset(value uint64) {
+	offset := Type(rs).Len() + 4
+	rs.ensureLen(offset + 8)
+	binary.LittleEndian.PutUint64(rs[offset:], value)
+}
+
+// ByteOffset returns the final byte offset of the stream.
+func (rs ResetStream) ByteOffset() uint64 {
+	offset := Type(rs).Len() + 4
+	rs.ensureLen(offset + 8)
+	return binary.LittleEndian.Uint64(rs[offset:])
+}
+
+// SetErrorCode sets the error code that explains why the stream has been reset.
+func (rs ResetStream) SetErrorCode(value uint32) {
+	offset := Type(rs).Len() + 4 + 8
+	rs.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(rs[offset:], value)
+}
+
+// ErrorCode returns the error code.
+func (rs ResetStream) ErrorCode() uint32 {
+	offset := Type(rs).Len() + 4 + 8
+	rs.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(rs[offset:])
+}
+
+// Len returns the length of the reset stream frame.
+func (rs ResetStream) Len() int {
+	return resetStreamLen
+}
+
+func (rs ResetStream) ensureLen(l int) {
+	if len(rs) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(rs)))
+	}
+}
@@ -0,0 +1,74 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestResetStream(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		streamID   uint32
+		byteOffset uint64
+		errorCode  uint32
+
+		bytes []byte
+	}{
+		{"Regular", 3, 0x0201, 0x06,
+			[]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00}},
+		{"Maximum", 0xffffffff, 0xffffffffffffffff, 0xffffffff,
+			[]byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				resetStream := frame.ResetStream(buffer)
+				resetStream.SetStreamID(testCase.streamID)
+				resetStream.SetByteOffset(testCase.byteOffset)
+				resetStream.SetErrorCode(testCase.errorCode)
+
+				assert.Equal(t, len(testCase.bytes), resetStream.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				resetStream := frame.ResetStream(testCase.bytes)
+				assert.Equal(t, testCase.streamID, resetStream.StreamID())
+				assert.Equal(t, testCase.byteOffset, resetStream.ByteOffset())
+				assert.Equal(t, testCase.errorCode, resetStream.ErrorCode())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				resetStream, err := frame.ParseResetStream(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				assert.Equal(t, testCase.bytes, []byte(resetStream))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseResetStream(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseResetStreamErrors(t *testing.T) {
+	_, err := frame.ParseResetStream([]byte{0x07})
+	assert.Equal(t, frame.ErrInvalidType, err)
+}
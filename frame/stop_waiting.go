@@ -0,0 +1,77 @@
+package frame
+
+import "fmt"
+
+// StopWaiting defines the stop waiting frame. It tells the peer to stop waiting for packets below
+// the least unacked packet number, which is encoded as delta to the enclosing packet's number. The
+// delta has the same length as the enclosing packet's packet number, so the frame doesn't encode it
+// and it has to be passed to the accessors.
+type StopWaiting []byte
+
+// StopWaitingLen returns the length of a stop waiting frame in a packet with the provided packet
+// number length.
+func StopWaitingLen(packetNumberLen int) int {
+	return 1 + packetNumberLen
+}
+
+// ParseStopWaiting validates the stop waiting frame at the beginning of the provided buffer and
+// returns it truncated to the frame's length. The packet number length has to be taken from the
+// enclosing packet (packet.Regular.PacketNumberLen) and has to be 1, 2, 4 or 6. Other lengths will
+// cause a panic. If the buffer is too short, ErrTruncated is returned. If the frame isn't a stop
+// waiting frame, ErrInvalidType is returned. The accessors of the returned frame don't panic.
+func ParseStopWaiting(b []byte, packetNumberLen int) (StopWaiting, error) {
+	if _, ok := ackLenFlag(packetNumberLen); !ok {
+		panic(fmt.Sprintf("cannot parse stop waiting with packet number length %d", packetNumberLen))
+	}
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeStopWaiting {
+		return nil, ErrInvalidType
+	}
+
+	l := StopWaitingLen(packetNumberLen)
+	if len(b) < l {
+		return nil, ErrTruncated
+	}
+	return StopWaiting(b[:l]), nil
+}
+
+// SetLeastUnackedDelta sets the delta between the enclosing packet's number and the least unacked
+// packet number. The length has to match the enclosing packet's packet number length and has to be
+// 1, 2, 4 or 6. Other lengths will cause a panic.
+func (sw StopWaiting) SetLeastUnackedDelta(value uint64, length int) {
+	if _, ok := ackLenFlag(length); !ok {
+		panic(fmt.Sprintf("cannot set least unacked delta with length %d", length))
+	}
+	frameType := Type(sw)
+	frameType.SetType(TypeStopWaiting)
+	offset := frameType.Len()
+	sw.ensureLen(offset + length)
+	putUint(sw[offset:], value, length)
+}
+
+// LeastUnackedDelta returns the delta between the enclosing packet's number and the least unacked
+// packet number. The length has to be the enclosing packet's packet number length and has to be 1,
+// 2, 4 or 6. Other lengths will cause a panic.
+func (sw StopWaiting) LeastUnackedDelta(packetNumberLen int) uint64 {
+	if _, ok := ackLenFlag(packetNumberLen); !ok {
+		panic(fmt.Sprintf("cannot get least unacked delta with length %d", packetNumberLen))
+	}
+	offset := Type(sw).Len()
+	sw.ensureLen(offset + packetNumberLen)
+	return getUint(sw[offset:], packetNumberLen)
+}
+
+// Len returns the length of the stop waiting frame. Since the frame doesn't encode its length, the
+// view has to be truncated to StopWaitingLen, as done by ParseStopWaiting.
+func (sw StopWaiting) Len() int {
+	return len(sw)
+}
+
+func (sw StopWaiting) ensureLen(l int) {
+	if len(sw) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(sw)))
+	}
+}
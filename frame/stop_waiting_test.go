@@ -0,0 +1,74 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestStopWaiting(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		leastUnackedDelta uint64
+		packetNumberLen   int
+
+		bytes []byte
+	}{
+		{"PacketNumberLen1", 0x01, 1, []byte{0x06, 0x01}},
+		{"PacketNumberLen2", 0x0201, 2, []byte{0x06, 0x01, 0x02}},
+		{"PacketNumberLen4", 0x04030201, 4, []byte{0x06, 0x01, 0x02, 0x03, 0x04}},
+		{"PacketNumberLen6", 0x060504030201, 6, []byte{0x06, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				stopWaiting := frame.StopWaiting(buffer)
+				stopWaiting.SetLeastUnackedDelta(testCase.leastUnackedDelta, testCase.packetNumberLen)
+
+				assert.Equal(t, frame.StopWaitingLen(testCase.packetNumberLen), stopWaiting.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				// The frame is followed by a ping frame.
+				stopWaiting := frame.StopWaiting(append(testCase.bytes, 0x07))
+				assert.Equal(t, testCase.leastUnackedDelta, stopWaiting.LeastUnackedDelta(testCase.packetNumberLen))
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				stopWaiting, err := frame.ParseStopWaiting(append(testCase.bytes, 0x07), testCase.packetNumberLen)
+				require.NoError(t, err)
+				assert.Equal(t, len(testCase.bytes), stopWaiting.Len())
+				assert.Equal(t, testCase.leastUnackedDelta, stopWaiting.LeastUnackedDelta(testCase.packetNumberLen))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseStopWaiting(testCase.bytes[:l], testCase.packetNumberLen)
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseStopWaitingErrors(t *testing.T) {
+	_, err := frame.ParseStopWaiting([]byte{0x07, 0x01}, 1)
+	assert.Equal(t, frame.ErrInvalidType, err)
+
+	assert.Panics(t, func() { frame.ParseStopWaiting([]byte{0x06, 0x01, 0x02, 0x03}, 3) })
+	assert.Panics(t, func() { frame.StopWaiting([]byte{0x06, 0x01, 0x02, 0x03}).LeastUnackedDelta(3) })
+}
@@ -8,100 +8,122 @@ import (
 // Stream defines the stream frame.
 type Stream []byte
 
-// SetStreamID sets the stream id.
-func (s Stream) SetStreamID(value interface{}) {
+// MinStreamIDLen returns the shortest length in bytes the provided stream id can be encoded with.
+func MinStreamIDLen(value uint32) int {
+	return minUintLen(uint64(value), 1)
+}
+
+// MinOffsetLen returns the shortest length in bytes the provided offset can be encoded with. For
+// a zero offset, zero is returned, since the offset can be omitted.
+func MinOffsetLen(value uint64) int {
+	if value == 0 {
+		return 0
+	}
+	return minUintLen(value, 2)
+}
+
+// ParseStream validates the stream frame at the beginning of the provided buffer and returns it
+// truncated to the frame's length. A frame without data length field extends to the end of the
+// buffer, so the buffer has to end with the enclosing packet's payload. If the buffer is too short,
+// ErrTruncated is returned. If the frame isn't a stream frame, ErrInvalidType is returned. The
+// accessors of the returned frame don't panic.
+func ParseStream(b []byte) (Stream, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeStream {
+		return nil, ErrInvalidType
+	}
+
+	s := Stream(b)
+	l := frameType.Len() + s.streamIDLen() + s.offsetLen()
+	if frameType.Flags()&FlagDataLen != 0x00 {
+		if len(s) < l+2 {
+			return nil, ErrTruncated
+		}
+		l += 2 + int(binary.LittleEndian.Uint16(s[l:]))
+	}
+	if len(s) < l {
+		return nil, ErrTruncated
+	}
+	if frameType.Flags()&FlagDataLen == 0x00 {
+		return s, nil
+	}
+	return s[:l], nil
+}
+
+// SetStreamID sets the stream id with the provided length in bytes. The length has to be between 1
+// and 4. Other lengths will cause a panic. MinStreamIDLen returns the shortest length for a value.
+func (s Stream) SetStreamID(value uint32, length int) {
+	if length < 1 || length > 4 {
+		panic(fmt.Sprintf("cannot set stream id with length %d", length))
+	}
 	frameType := Type(s)
 	frameType.SetType(TypeStream)
 	offset := frameType.Len()
+	s.ensureLen(offset + length)
 
-	switch v := value.(type) {
-	case uint32:
-		s.ensureLen(offset + 4)
-		frameType.SetFlags(FlagStreamIDLen4)
-		binary.LittleEndian.PutUint32(s[offset:], v)
-	case uint16:
-		s.ensureLen(offset + 2)
-		frameType.SetFlags(FlagStreamIDLen2)
-		binary.LittleEndian.PutUint16(s[offset:], v)
-	case uint8:
-		s.ensureLen(offset + 1)
-		frameType.SetFlags(FlagStreamIDLen1)
-		s[offset] = v
-	default:
-		panic(fmt.Sprintf("cannot set stream id value of type %T", v))
-	}
+	frameType.SetFlags(uint8(length - 1))
+	putUint(s[offset:], uint64(value), length)
 }
 
 // StreamID returns the stream id.
-func (s Stream) StreamID() interface{} {
-	frameType := Type(s)
-	offset := frameType.Len()
+func (s Stream) StreamID() uint32 {
+	offset := Type(s).Len()
+	l := s.streamIDLen()
+	s.ensureLen(offset + l)
+	return uint32(getUint(s[offset:], l))
+}
 
-	switch v := frameType.Flags() & MaskStreamIDLen; v {
-	case FlagStreamIDLen4:
-		s.ensureLen(offset + 4)
-		return binary.LittleEndian.Uint32(s[offset:])
-	case FlagStreamIDLen2:
-		s.ensureLen(offset + 2)
-		return binary.LittleEndian.Uint16(s[offset:])
-	case FlagStreamIDLen1:
-		s.ensureLen(offset + 1)
-		return s[offset]
-	default:
-		panic(fmt.Sprintf("cannot get stream id value of type %x", v))
-	}
+// StreamIDLen returns the length of the stream id in bytes.
+func (s Stream) StreamIDLen() int {
+	return s.streamIDLen()
 }
 
-// AddOffset adds the offset.
-func (s Stream) AddOffset(value interface{}) {
+// AddOffset adds the offset with the provided length in bytes. The length has to be between 2 and
+// 8. Other lengths will cause a panic. MinOffsetLen returns the shortest length for a value.
+func (s Stream) AddOffset(value uint64, length int) {
+	if length < 2 || length > 8 {
+		panic(fmt.Sprintf("cannot set offset with length %d", length))
+	}
 	frameType := Type(s)
 	offset := frameType.Len() + s.streamIDLen()
+	s.ensureLen(offset + length)
 
-	switch v := value.(type) {
-	case uint64:
-		s.ensureLen(offset + 8)
-		frameType.SetFlags(FlagOffsetLen8)
-		binary.LittleEndian.PutUint64(s[offset:], v)
-	case uint32:
-		s.ensureLen(offset + 4)
-		frameType.SetFlags(FlagOffsetLen4)
-		binary.LittleEndian.PutUint32(s[offset:], v)
-	case uint16:
-		s.ensureLen(offset + 2)
-		frameType.SetFlags(FlagOffsetLen2)
-		binary.LittleEndian.PutUint16(s[offset:], v)
-	}
+	frameType.SetFlags(uint8(length-1) << 2)
+	putUint(s[offset:], value, length)
 }
 
-// Offset returns the offset.
-func (s Stream) Offset() interface{} {
-	frameType := Type(s)
-	offset := frameType.Len() + s.streamIDLen()
+// Offset returns the offset. If the frame has no offset, zero is returned.
+func (s Stream) Offset() uint64 {
+	offset := Type(s).Len() + s.streamIDLen()
+	l := s.offsetLen()
+	s.ensureLen(offset + l)
+	return getUint(s[offset:], l)
+}
 
-	switch v := frameType.Flags() & MaskOffsetLen; v {
-	case FlagOffsetLen8:
-		s.ensureLen(offset + 8)
-		return binary.LittleEndian.Uint64(s[offset:])
-	case FlagOffsetLen4:
-		s.ensureLen(offset + 4)
-		return binary.LittleEndian.Uint32(s[offset:])
-	case FlagOffsetLen2:
-		s.ensureLen(offset + 2)
-		return binary.LittleEndian.Uint16(s[offset:])
-	case FlagOffsetLen0:
-		return nil
-	default:
-		panic(fmt.Sprintf("cannot get stream id value of type %x", v))
-	}
+// OffsetLen returns the length of the offset in bytes.
+func (s Stream) OffsetLen() int {
+	return s.offsetLen()
+}
+
+// SetFin sets the fin flag, which marks the frame as the last one of the stream. Since SetStreamID
+// sets the frame type, it has to be called after SetStreamID.
+func (s Stream) SetFin() {
+	Type(s).SetFlags(FlagFinish)
+}
+
+// Fin returns true, if the fin flag is set.
+func (s Stream) Fin() bool {
+	return Type(s).Flags()&FlagFinish != 0x00
 }
 
-// SetData sets the payload data.
+// SetData sets the payload data preceded by the data length field. Empty data gets a zero data
+// length field, so frames without data, e.g. ones that only carry the fin flag, can be followed by
+// other frames.
 func (s Stream) SetData(data []byte) {
 	frameType := Type(s)
-	if len(data) == 0 {
-		return
-	}
-
 	offset := frameType.Len() + s.streamIDLen() + s.offsetLen()
 	s.ensureLen(offset + 2 + len(data))
 
@@ -111,14 +133,26 @@ func (s Stream) SetData(data []byte) {
 	copy(s[offset+2:], data)
 }
 
-// Data returns the payload data.
+// SetImplicitData sets the payload data without the data length field. Such a frame extends to the
+// end of the packet, so it has to be the last frame in the packet and the buffer has to end with the
+// data.
+func (s Stream) SetImplicitData(data []byte) {
+	offset := Type(s).Len() + s.streamIDLen() + s.offsetLen()
+	s.ensureLen(offset + len(data))
+
+	copy(s[offset:], data)
+}
+
+// Data returns the payload data. If the frame has no data length field, the data extends to the
+// end of the buffer.
 func (s Stream) Data() []byte {
 	frameType := Type(s)
+	offset := frameType.Len() + s.streamIDLen() + s.offsetLen()
 	if frameType.Flags()&FlagDataLen == 0x00 {
-		return []byte{}
+		s.ensureLen(offset)
+		return s[offset:]
 	}
 
-	offset := frameType.Len() + s.streamIDLen() + s.offsetLen()
 	s.ensureLen(offset + 2)
 
 	l := int(binary.LittleEndian.Uint16(s[offset:]))
@@ -128,7 +162,8 @@ func (s Stream) Data() []byte {
 	return s[offset : offset+l]
 }
 
-// Len returns the length of the stream frame.
+// Len returns the length of the stream frame. If the frame has no data length field, it's bounded
+// by the end of the buffer.
 func (s Stream) Len() int {
 	return Type(s).Len() + s.streamIDLen() + s.offsetLen() + s.dataLen()
 }
@@ -140,48 +175,27 @@ func (s Stream) ensureLen(l int) {
 }
 
 func (s Stream) streamIDLen() int {
-	switch Type(s).Flags() & MaskStreamIDLen {
-	case FlagStreamIDLen4:
-		return 4
-	case FlagStreamIDLen3:
-		return 3
-	case FlagStreamIDLen2:
-		return 2
-	case FlagStreamIDLen1:
-		return 1
-	}
-	return 1
+	return int(Type(s).Flags()&MaskStreamIDLen) + 1
 }
 
 func (s Stream) offsetLen() int {
-	switch Type(s).Flags() & MaskOffsetLen {
-	case FlagOffsetLen8:
-		return 8
-	case FlagOffsetLen7:
-		return 7
-	case FlagOffsetLen6:
-		return 6
-	case FlagOffsetLen5:
-		return 5
-	case FlagOffsetLen4:
-		return 4
-	case FlagOffsetLen3:
-		return 3
-	case FlagOffsetLen2:
-		return 2
-	case FlagOffsetLen0:
+	l := int(Type(s).Flags()&MaskOffsetLen) >> 2
+	if l == 0 {
 		return 0
 	}
-	return 0
+	return l + 1
 }
 
 func (s Stream) dataLen() int {
 	frameType := Type(s)
+	offset := frameType.Len() + s.streamIDLen() + s.offsetLen()
 	if frameType.Flags()&FlagDataLen == 0x00 {
-		return 0
+		if len(s) < offset {
+			return 0
+		}
+		return len(s) - offset
 	}
 
-	offset := frameType.Len() + s.streamIDLen() + s.offsetLen()
 	s.ensureLen(offset + 2)
 
 	return 2 + int(binary.LittleEndian.Uint16(s[offset:]))
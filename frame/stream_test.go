@@ -1,9 +1,11 @@
 package frame_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/simia-tech/go-quic/frame"
 )
@@ -12,21 +14,43 @@ func TestStream(t *testing.T) {
 	testCases := []struct {
 		name string
 
-		streamID interface{}
-		offset   interface{}
-		data     []byte
+		streamID    uint32
+		streamIDLen int
+		offset      uint64
+		offsetLen   int
+		fin         bool
+		implicit    bool
+		data        []byte
 
 		bytes []byte
 	}{
-		{"RegularStreamID4Offset8", uint32(1), uint64(2), []byte{0x03, 0x04, 0x05},
+		{"RegularStreamID4Offset8", 1, 4, 2, 8, false, false, []byte{0x03, 0x04, 0x05},
 			[]byte{0xbf, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
-		{"RegularStreamID2Offset4", uint16(1), uint32(2), []byte{0x03, 0x04, 0x05},
+		{"RegularStreamID2Offset4", 1, 2, 2, 4, false, false, []byte{0x03, 0x04, 0x05},
 			[]byte{0xad, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
-		{"RegularStreamID1Offset2", uint8(1), uint16(2), []byte{0x03, 0x04, 0x05},
+		{"RegularStreamID1Offset2", 1, 1, 2, 2, false, false, []byte{0x03, 0x04, 0x05},
 			[]byte{0xa4, 0x01, 0x02, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
-		{"RegularStreamID1Offset0", uint8(1), nil, []byte{0x03, 0x04, 0x05},
+		{"RegularStreamID1Offset0", 1, 1, 0, 0, false, false, []byte{0x03, 0x04, 0x05},
 			[]byte{0xa0, 0x01, 0x03, 0x00, 0x03, 0x04, 0x05}},
-		{"RegularNoData", uint32(1), uint64(2), []byte{},
+		{"RegularStreamID3Offset7", 0x030201, 3, 0x07060504030201, 7, false, false, []byte{0x03, 0x04, 0x05},
+			[]byte{0xba, 0x01, 0x02, 0x03, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x03, 0x00, 0x03, 0x04, 0x05}},
+		{"RegularStreamID3Offset6", 1, 3, 2, 6, false, false, []byte{0x03, 0x04, 0x05},
+			[]byte{0xb6, 0x01, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
+		{"RegularStreamID1Offset5", 1, 1, 2, 5, false, false, []byte{0x03, 0x04, 0x05},
+			[]byte{0xb0, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
+		{"RegularStreamID1Offset3", 1, 1, 2, 3, false, false, []byte{0x03, 0x04, 0x05},
+			[]byte{0xa8, 0x01, 0x02, 0x00, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
+		{"Fin", 1, 1, 2, 2, true, false, []byte{0x03, 0x04, 0x05},
+			[]byte{0xe4, 0x01, 0x02, 0x00, 0x03, 0x00, 0x03, 0x04, 0x05}},
+		{"FinNoData", 1, 1, 2, 2, true, false, []byte{},
+			[]byte{0xe4, 0x01, 0x02, 0x00, 0x00, 0x00}},
+		{"ImplicitFinNoData", 1, 1, 2, 2, true, true, []byte{},
+			[]byte{0xc4, 0x01, 0x02, 0x00}},
+		{"Implicit", 1, 1, 2, 2, false, true, []byte{0x03, 0x04, 0x05},
+			[]byte{0x84, 0x01, 0x02, 0x00, 0x03, 0x04, 0x05}},
+		{"ImplicitFin", 1, 4, 2, 8, true, true, []byte{0x03},
+			[]byte{0xdf, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03}},
+		{"ImplicitNoData", 1, 4, 2, 8, false, true, []byte{},
 			[]byte{0x9f, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
 	}
 
@@ -36,11 +60,18 @@ func TestStream(t *testing.T) {
 				buffer := make([]byte, len(testCase.bytes))
 
 				stream := frame.Stream(buffer)
-				stream.SetStreamID(testCase.streamID)
-				if testCase.offset != nil {
-					stream.AddOffset(testCase.offset)
+				stream.SetStreamID(testCase.streamID, testCase.streamIDLen)
+				if testCase.offsetLen > 0 {
+					stream.AddOffset(testCase.offset, testCase.offsetLen)
+				}
+				if testCase.fin {
+					stream.SetFin()
+				}
+				if testCase.implicit {
+					stream.SetImplicitData(testCase.data)
+				} else {
+					stream.SetData(testCase.data)
 				}
-				stream.SetData(testCase.data)
 
 				assert.Equal(t, len(testCase.bytes), stream.Len())
 				assert.Equal(t, testCase.bytes, buffer)
@@ -53,9 +84,102 @@ func TestStream(t *testing.T) {
 			t.Run(testCase.name, func(t *testing.T) {
 				stream := frame.Stream(testCase.bytes)
 				assert.Equal(t, testCase.streamID, stream.StreamID())
+				assert.Equal(t, testCase.streamIDLen, stream.StreamIDLen())
 				assert.Equal(t, testCase.offset, stream.Offset())
+				assert.Equal(t, testCase.offsetLen, stream.OffsetLen())
+				assert.Equal(t, testCase.fin, stream.Fin())
 				assert.Equal(t, testCase.data, stream.Data())
 			})
 		}
 	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				stream, err := frame.ParseStream(testCase.bytes)
+				require.NoError(t, err)
+				assert.Equal(t, len(testCase.bytes), stream.Len())
+				assert.Equal(t, testCase.data, stream.Data())
+
+				// A frame with data length field ends before trailing bytes, otherwise they are data.
+				stream, err = frame.ParseStream(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				if !testCase.implicit {
+					assert.Equal(t, len(testCase.bytes), stream.Len())
+				} else {
+					assert.Equal(t, len(testCase.bytes)+1, stream.Len())
+					assert.Equal(t, append(testCase.data, 0xff), stream.Data())
+				}
+
+				truncatedLen := len(testCase.bytes)
+				if testCase.implicit {
+					// Without data length field, every truncation after the header is still valid.
+					truncatedLen -= len(testCase.data)
+					for l := truncatedLen; l < len(testCase.bytes); l++ {
+						stream, err := frame.ParseStream(testCase.bytes[:l])
+						require.NoError(t, err)
+						assert.Equal(t, testCase.data[:l-truncatedLen], stream.Data())
+					}
+				}
+				for l := 0; l < truncatedLen; l++ {
+					_, err := frame.ParseStream(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseStreamErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		bytes []byte
+		err   error
+	}{
+		{"Empty", []byte{}, frame.ErrTruncated},
+		{"Ping", []byte{0x07}, frame.ErrInvalidType},
+		{"UnknownType", []byte{0x3f}, frame.ErrInvalidType},
+		{"TruncatedStreamID", []byte{0x82, 0x01, 0x00}, frame.ErrTruncated},
+		{"TruncatedOffset", []byte{0x90, 0x01, 0x00, 0x00, 0x00, 0x00}, frame.ErrTruncated},
+		{"TruncatedData", []byte{0xa0, 0x01, 0x03, 0x00, 0x03}, frame.ErrTruncated},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := frame.ParseStream(testCase.bytes)
+			assert.Equal(t, testCase.err, err)
+		})
+	}
+}
+
+func TestMinLen(t *testing.T) {
+	testCases := []struct {
+		value             uint64
+		expectStreamIDLen int
+		expectOffsetLen   int
+	}{
+		{0x00, 1, 0},
+		{0x01, 1, 2},
+		{0xff, 1, 2},
+		{0x0100, 2, 2},
+		{0xffff, 2, 2},
+		{0x010000, 3, 3},
+		{0xffffff, 3, 3},
+		{0x01000000, 4, 4},
+		{0xffffffff, 4, 4},
+		{0x0100000000, 0, 5},
+		{0x010000000000, 0, 6},
+		{0x01000000000000, 0, 7},
+		{0x0100000000000000, 0, 8},
+		{0xffffffffffffffff, 0, 8},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%x", testCase.value), func(t *testing.T) {
+			if testCase.expectStreamIDLen > 0 {
+				assert.Equal(t, testCase.expectStreamIDLen, frame.MinStreamIDLen(uint32(testCase.value)))
+			}
+			assert.Equal(t, testCase.expectOffsetLen, frame.MinOffsetLen(testCase.value))
+		})
+	}
 }
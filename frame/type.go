@@ -56,6 +56,19 @@ const (
 // Type defines the frame type.
 type Type []byte
 
+// ParseType validates the frame type at the beginning of the provided buffer. If the buffer is
+// empty, ErrTruncated is returned. If the type is unknown, ErrInvalidType is returned.
+func ParseType(b []byte) (Type, error) {
+	if len(b) < 1 {
+		return nil, ErrTruncated
+	}
+	t := Type(b)
+	if t.Type() > TypePing && t.Type() != TypeStream && t.Type() != TypeAcknowledge {
+		return nil, ErrInvalidType
+	}
+	return t, nil
+}
+
 // SetType sets the frame type.
 func (t Type) SetType(value uint8) {
 	t.ensureLen(1)
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/simia-tech/go-quic/frame"
 )
@@ -65,4 +66,14 @@ func TestType(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				ft, err := frame.ParseType(testCase.bytes)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.frameType, ft.Type())
+			})
+		}
+	})
 }
@@ -0,0 +1,43 @@
+package frame
+
+// Parameters of the 16 bit unsigned float format, that has 11 explicit mantissa bits and 5 exponent
+// bits.
+const (
+	ufloat16ExponentBits          = 5
+	ufloat16MaxExponent           = 1<<ufloat16ExponentBits - 2
+	ufloat16MantissaBits          = 16 - ufloat16ExponentBits
+	ufloat16MantissaEffectiveBits = ufloat16MantissaBits + 1
+	ufloat16MaxValue              = (1<<ufloat16MantissaEffectiveBits - 1) << ufloat16MaxExponent
+)
+
+// EncodeUFloat16 encodes the provided value into the 16 bit unsigned float format. Values that
+// exceed the range of the format are encoded as the maximum value. Precision is lost for values
+// above 4095.
+func EncodeUFloat16(value uint64) uint16 {
+	if value < 1<<ufloat16MantissaEffectiveBits {
+		return uint16(value)
+	}
+	if value >= ufloat16MaxValue {
+		return 0xffff
+	}
+
+	exponent := uint64(0)
+	for offset := uint(16); offset > 0; offset /= 2 {
+		if value >= 1<<(ufloat16MantissaBits+offset) {
+			exponent += uint64(offset)
+			value >>= offset
+		}
+	}
+	return uint16(value + exponent<<ufloat16MantissaBits)
+}
+
+// DecodeUFloat16 decodes the provided value from the 16 bit unsigned float format.
+func DecodeUFloat16(value uint16) uint64 {
+	v := uint64(value)
+	if v < 1<<ufloat16MantissaEffectiveBits {
+		return v
+	}
+	exponent := v>>ufloat16MantissaBits - 1
+	v -= exponent << ufloat16MantissaBits
+	return v << exponent
+}
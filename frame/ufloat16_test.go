@@ -0,0 +1,46 @@
+package frame_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestUFloat16(t *testing.T) {
+	testCases := []struct {
+		value   uint64
+		encoded uint16
+	}{
+		{0, 0x0000},
+		{1, 0x0001},
+		{4095, 0x0fff},
+		{4096, 0x1000},
+		{4097, 0x1000},
+		{4098, 0x1001},
+		{8190, 0x17ff},
+		{8192, 0x1800},
+		{0x3ffc0000000, 0xffff},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%d", testCase.value), func(t *testing.T) {
+			assert.Equal(t, testCase.encoded, frame.EncodeUFloat16(testCase.value))
+		})
+	}
+
+	t.Run("Overflow", func(t *testing.T) {
+		assert.Equal(t, uint16(0xffff), frame.EncodeUFloat16(1<<63))
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		for encoded := 0; encoded <= 0xffff; encoded++ {
+			value := frame.DecodeUFloat16(uint16(encoded))
+			if !assert.Equal(t, uint16(encoded), frame.EncodeUFloat16(value), "encoded value %x", encoded) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package frame
+
+// putUint writes the lowest length bytes of the value in little endian order.
+func putUint(b []byte, value uint64, length int) {
+	for index := 0; index < length; index++ {
+		b[index] = uint8(value >> uint(8*index))
+	}
+}
+
+// getUint reads a little endian value of the provided length.
+func getUint(b []byte, length int) uint64 {
+	value := uint64(0)
+	for index := length - 1; index >= 0; index-- {
+		value = value<<8 | uint64(b[index])
+	}
+	return value
+}
+
+// minUintLen returns the shortest length in bytes, but at least min, that holds the value.
+func minUintLen(value uint64, min int) int {
+	l := min
+	for l < 8 && value>>uint(8*l) != 0 {
+		l++
+	}
+	return l
+}
@@ -0,0 +1,72 @@
+package frame
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// WindowUpdate defines the window update frame. It raises the flow control window of a stream or,
+// with stream id zero, of the whole connection to the provided byte offset.
+type WindowUpdate []byte
+
+// windowUpdateLen defines the length of a window update frame.
+const windowUpdateLen = 1 + 4 + 8
+
+// ParseWindowUpdate validates the window update frame at the beginning of the provided buffer and
+// returns it truncated to the frame's length. If the buffer is too short, ErrTruncated is returned.
+// If the frame isn't a window update frame, ErrInvalidType is returned. The accessors of the
+// returned frame don't panic.
+func ParseWindowUpdate(b []byte) (WindowUpdate, error) {
+	frameType, err := ParseType(b)
+	if err != nil {
+		return nil, err
+	}
+	if frameType.Type() != TypeWindowUpdate {
+		return nil, ErrInvalidType
+	}
+	if len(b) < windowUpdateLen {
+		return nil, ErrTruncated
+	}
+	return WindowUpdate(b[:windowUpdateLen]), nil
+}
+
+// SetStreamID sets the stream id. Zero refers to the connection.
+func (wu WindowUpdate) SetStreamID(value uint32) {
+	frameType := Type(wu)
+	frameType.SetType(TypeWindowUpdate)
+	offset := frameType.Len()
+	wu.ensureLen(offset + 4)
+	binary.LittleEndian.PutUint32(wu[offset:], value)
+}
+
+// StreamID returns the stream id.
+func (wu WindowUpdate) StreamID() uint32 {
+	offset := Type(wu).Len()
+	wu.ensureLen(offset + 4)
+	return binary.LittleEndian.Uint32(wu[offset:])
+}
+
+// SetByteOffset sets the absolute byte offset up to which the peer may send data.
+func (wu WindowUpdate) SetByteOffset(value uint64) {
+	offset := Type(wu).Len() + 4
+	wu.ensureLen(offset + 8)
+	binary.LittleEndian.PutUint64(wu[offset:], value)
+}
+
+// ByteOffset returns the absolute byte offset up to which the peer may send data.
+func (wu WindowUpdate) ByteOffset() uint64 {
+	offset := Type(wu).Len() + 4
+	wu.ensureLen(offset + 8)
+	return binary.LittleEndian.Uint64(wu[offset:])
+}
+
+// Len returns the length of the window update frame.
+func (wu WindowUpdate) Len() int {
+	return windowUpdateLen
+}
+
+func (wu WindowUpdate) ensureLen(l int) {
+	if len(wu) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(wu)))
+	}
+}
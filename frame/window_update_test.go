@@ -0,0 +1,71 @@
+package frame_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+func TestWindowUpdate(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		streamID   uint32
+		byteOffset uint64
+
+		bytes []byte
+	}{
+		{"Stream", 3, 0x4000,
+			[]byte{0x04, 0x03, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"Connection", 0, 0x0807060504030201,
+			[]byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				buffer := make([]byte, len(testCase.bytes))
+
+				windowUpdate := frame.WindowUpdate(buffer)
+				windowUpdate.SetStreamID(testCase.streamID)
+				windowUpdate.SetByteOffset(testCase.byteOffset)
+
+				assert.Equal(t, len(testCase.bytes), windowUpdate.Len())
+				assert.Equal(t, testCase.bytes, buffer)
+			})
+		}
+	})
+
+	t.Run("Read", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				windowUpdate := frame.WindowUpdate(testCase.bytes)
+				assert.Equal(t, testCase.streamID, windowUpdate.StreamID())
+				assert.Equal(t, testCase.byteOffset, windowUpdate.ByteOffset())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				windowUpdate, err := frame.ParseWindowUpdate(append(testCase.bytes, 0xff))
+				require.NoError(t, err)
+				assert.Equal(t, testCase.bytes, []byte(windowUpdate))
+
+				for l := 0; l < len(testCase.bytes); l++ {
+					_, err := frame.ParseWindowUpdate(testCase.bytes[:l])
+					assert.Equal(t, frame.ErrTruncated, err, "length %d", l)
+				}
+			})
+		}
+	})
+}
+
+func TestParseWindowUpdateErrors(t *testing.T) {
+	_, err := frame.ParseWindowUpdate([]byte{0x05})
+	assert.Equal(t, frame.ErrInvalidType, err)
+}
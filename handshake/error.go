@@ -0,0 +1,13 @@
+package handshake
+
+import "errors"
+
+// Errors returned by Marshal and Unmarshal.
+var (
+	ErrTruncated       = errors.New("handshake: truncated")
+	ErrTooManyEntries  = errors.New("handshake: too many entries")
+	ErrValueTooLarge   = errors.New("handshake: value too large")
+	ErrInvalidTagOrder = errors.New("handshake: tags not in ascending order")
+	ErrInvalidOffset   = errors.New("handshake: invalid value offset")
+	ErrTrailingData    = errors.New("handshake: trailing data")
+)
@@ -0,0 +1,176 @@
+package handshake
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Limits of a message.
+const (
+	MaxEntries  = 128
+	MaxValueLen = 4000
+)
+
+// The message header consists of the message tag, the number of entries and two bytes of padding.
+// Each entry consists of the value tag and the end offset of the value.
+const (
+	headerLen = 4 + 2 + 2
+	entryLen  = 4 + 4
+)
+
+// maxStringValueLen defines the number of value bytes that are printed by String.
+const maxStringValueLen = 32
+
+// Message defines a handshake message. It consists of a tag and a set of values that are
+// identified by tags. On the wire, the values are ordered by their tags.
+type Message struct {
+	Tag    Tag
+	Values map[Tag][]byte
+}
+
+// NewMessage returns an empty message with the provided tag.
+func NewMessage(tag Tag) *Message {
+	return &Message{Tag: tag, Values: make(map[Tag][]byte)}
+}
+
+// Set sets the value of the provided tag.
+func (m *Message) Set(tag Tag, value []byte) {
+	if m.Values == nil {
+		m.Values = make(map[Tag][]byte)
+	}
+	m.Values[tag] = value
+}
+
+// Get returns the value of the provided tag. If the message has no such value, false is returned.
+func (m *Message) Get(tag Tag) ([]byte, bool) {
+	value, ok := m.Values[tag]
+	return value, ok
+}
+
+// Tags returns the tags of the values in ascending order.
+func (m *Message) Tags() []Tag {
+	tags := make([]Tag, 0, len(m.Values))
+	for tag := range m.Values {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tags
+}
+
+// Len returns the length of the marshaled message.
+func (m *Message) Len() int {
+	l := headerLen + len(m.Values)*entryLen
+	for _, value := range m.Values {
+		l += len(value)
+	}
+	return l
+}
+
+// Marshal returns the wire representation of the message. If the message has more than MaxEntries
+// values, ErrTooManyEntries is returned. If a value is longer than MaxValueLen, ErrValueTooLarge is
+// returned.
+func (m *Message) Marshal() ([]byte, error) {
+	if len(m.Values) > MaxEntries {
+		return nil, ErrTooManyEntries
+	}
+	for _, value := range m.Values {
+		if len(value) > MaxValueLen {
+			return nil, ErrValueTooLarge
+		}
+	}
+
+	b := make([]byte, m.Len())
+	binary.LittleEndian.PutUint32(b, uint32(m.Tag))
+	binary.LittleEndian.PutUint16(b[4:], uint16(len(m.Values)))
+
+	entryOffset, valueOffset, end := headerLen, headerLen+len(m.Values)*entryLen, 0
+	for _, tag := range m.Tags() {
+		value := m.Values[tag]
+		copy(b[valueOffset+end:], value)
+		end += len(value)
+
+		binary.LittleEndian.PutUint32(b[entryOffset:], uint32(tag))
+		binary.LittleEndian.PutUint32(b[entryOffset+4:], uint32(end))
+		entryOffset += entryLen
+	}
+
+	return b, nil
+}
+
+// Unmarshal sets the message to the message in the provided buffer. The values refer to the
+// buffer, so it must not be modified while the message is used. If the buffer is too short,
+// ErrTruncated is returned and if it holds more than the message, ErrTrailingData is returned. If
+// the limits are exceeded, ErrTooManyEntries or ErrValueTooLarge is returned. If the tags aren't in
+// strictly ascending order, ErrInvalidTagOrder is returned and if the end offsets are decreasing,
+// ErrInvalidOffset is returned.
+func (m *Message) Unmarshal(b []byte) error {
+	if len(b) < headerLen {
+		return ErrTruncated
+	}
+	count := int(binary.LittleEndian.Uint16(b[4:]))
+	if count > MaxEntries {
+		return ErrTooManyEntries
+	}
+	valueOffset := headerLen + count*entryLen
+	if len(b) < valueOffset {
+		return ErrTruncated
+	}
+
+	values := make(map[Tag][]byte, count)
+	previous, start := Tag(0), 0
+	for index := 0; index < count; index++ {
+		entry := b[headerLen+index*entryLen:]
+		tag := Tag(binary.LittleEndian.Uint32(entry))
+		end := int(binary.LittleEndian.Uint32(entry[4:]))
+		if index > 0 && tag <= previous {
+			return ErrInvalidTagOrder
+		}
+		if end < start {
+			return ErrInvalidOffset
+		}
+		if end-start > MaxValueLen {
+			return ErrValueTooLarge
+		}
+		if len(b) < valueOffset+end {
+			return ErrTruncated
+		}
+		values[tag] = b[valueOffset+start : valueOffset+end]
+		previous, start = tag, end
+	}
+	if len(b) > valueOffset+start {
+		return ErrTrailingData
+	}
+
+	m.Tag = Tag(binary.LittleEndian.Uint32(b))
+	m.Values = values
+	return nil
+}
+
+// String returns a readable representation of the message. Printable values are quoted, other
+// values are printed in hex. Long values are shortened.
+func (m *Message) String() string {
+	entries := make([]string, 0, len(m.Values))
+	for _, tag := range m.Tags() {
+		entries = append(entries, fmt.Sprintf("%s: %s", tag, formatValue(m.Values[tag])))
+	}
+	return fmt.Sprintf("%s{%s}", m.Tag, strings.Join(entries, ", "))
+}
+
+func formatValue(value []byte) string {
+	suffix := ""
+	if len(value) > maxStringValueLen {
+		suffix = fmt.Sprintf("...(%d bytes)", len(value))
+		value = value[:maxStringValueLen]
+	}
+	if len(value) == 0 {
+		return `""` + suffix
+	}
+	for _, c := range value {
+		if c < 0x20 || c > 0x7e {
+			return fmt.Sprintf("0x%x", value) + suffix
+		}
+	}
+	return fmt.Sprintf("%q", value) + suffix
+}
@@ -0,0 +1,135 @@
+package handshake_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/handshake"
+)
+
+func TestMessage(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tag    handshake.Tag
+		values map[handshake.Tag][]byte
+		bytes  []byte
+		text   string
+	}{
+		{"Empty", handshake.TagSHLO, map[handshake.Tag][]byte{},
+			[]byte{'S', 'H', 'L', 'O', 0x00, 0x00, 0x00, 0x00},
+			"SHLO{}"},
+		{"Values", handshake.TagCHLO, map[handshake.Tag][]byte{
+			handshake.TagVER: []byte("Q039"),
+			handshake.TagPAD: {0x00, 0x00},
+			handshake.TagSNI: []byte("a.b"),
+		},
+			[]byte{'C', 'H', 'L', 'O', 0x03, 0x00, 0x00, 0x00,
+				'P', 'A', 'D', 0x00, 0x02, 0x00, 0x00, 0x00,
+				'S', 'N', 'I', 0x00, 0x05, 0x00, 0x00, 0x00,
+				'V', 'E', 'R', 0x00, 0x09, 0x00, 0x00, 0x00,
+				0x00, 0x00, 'a', '.', 'b', 'Q', '0', '3', '9'},
+			`CHLO{PAD: 0x0000, SNI: "a.b", VER: "Q039"}`},
+		{"EmptyValue", handshake.TagREJ, map[handshake.Tag][]byte{
+			handshake.TagSTK:  {},
+			handshake.TagRREJ: {0x01},
+		},
+			[]byte{'R', 'E', 'J', 0x00, 0x02, 0x00, 0x00, 0x00,
+				'S', 'T', 'K', 0x00, 0x00, 0x00, 0x00, 0x00,
+				'R', 'R', 'E', 'J', 0x01, 0x00, 0x00, 0x00,
+				0x01},
+			`REJ{STK: "", RREJ: 0x01}`},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				message := handshake.NewMessage(testCase.tag)
+				for tag, value := range testCase.values {
+					message.Set(tag, value)
+				}
+
+				b, err := message.Marshal()
+				require.NoError(t, err)
+				assert.Equal(t, len(testCase.bytes), message.Len())
+				assert.Equal(t, testCase.bytes, b)
+				assert.Equal(t, testCase.text, message.String())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				message := &handshake.Message{}
+				require.NoError(t, message.Unmarshal(testCase.bytes))
+				assert.Equal(t, testCase.tag, message.Tag)
+				assert.Len(t, message.Values, len(testCase.values))
+				for tag, value := range testCase.values {
+					parsedValue, ok := message.Get(tag)
+					require.True(t, ok)
+					assert.Equal(t, value, parsedValue)
+				}
+
+				assert.Equal(t, handshake.ErrTruncated, message.Unmarshal(testCase.bytes[:len(testCase.bytes)-1]))
+				assert.Equal(t, handshake.ErrTrailingData, message.Unmarshal(append(testCase.bytes, 0x00)))
+			})
+		}
+	})
+}
+
+func TestMessageUnmarshalErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		bytes []byte
+		err   error
+	}{
+		{"TruncatedHeader", []byte{'C', 'H', 'L', 'O', 0x01, 0x00, 0x00}, handshake.ErrTruncated},
+		{"TruncatedEntries", []byte{'C', 'H', 'L', 'O', 0x01, 0x00, 0x00, 0x00, 'P', 'A', 'D', 0x00},
+			handshake.ErrTruncated},
+		{"TooManyEntries", []byte{'C', 'H', 'L', 'O', 0x81, 0x00, 0x00, 0x00}, handshake.ErrTooManyEntries},
+		{"InvalidTagOrder", []byte{'C', 'H', 'L', 'O', 0x02, 0x00, 0x00, 0x00,
+			'S', 'N', 'I', 0x00, 0x01, 0x00, 0x00, 0x00,
+			'P', 'A', 'D', 0x00, 0x02, 0x00, 0x00, 0x00,
+			0x00, 0x00}, handshake.ErrInvalidTagOrder},
+		{"DuplicateTag", []byte{'C', 'H', 'L', 'O', 0x02, 0x00, 0x00, 0x00,
+			'P', 'A', 'D', 0x00, 0x01, 0x00, 0x00, 0x00,
+			'P', 'A', 'D', 0x00, 0x02, 0x00, 0x00, 0x00,
+			0x00, 0x00}, handshake.ErrInvalidTagOrder},
+		{"InvalidOffset", []byte{'C', 'H', 'L', 'O', 0x02, 0x00, 0x00, 0x00,
+			'P', 'A', 'D', 0x00, 0x02, 0x00, 0x00, 0x00,
+			'S', 'N', 'I', 0x00, 0x01, 0x00, 0x00, 0x00,
+			0x00, 0x00}, handshake.ErrInvalidOffset},
+		{"ValueTooLarge", []byte{'C', 'H', 'L', 'O', 0x01, 0x00, 0x00, 0x00,
+			'P', 'A', 'D', 0x00, 0xa1, 0x0f, 0x00, 0x00}, handshake.ErrValueTooLarge},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			message := &handshake.Message{}
+			assert.Equal(t, testCase.err, message.Unmarshal(testCase.bytes))
+		})
+	}
+}
+
+func TestMessageMarshalErrors(t *testing.T) {
+	message := handshake.NewMessage(handshake.TagCHLO)
+	message.Set(handshake.TagPAD, make([]byte, handshake.MaxValueLen+1))
+	_, err := message.Marshal()
+	assert.Equal(t, handshake.ErrValueTooLarge, err)
+
+	message = handshake.NewMessage(handshake.TagCHLO)
+	for index := 0; index <= handshake.MaxEntries; index++ {
+		message.Set(handshake.Tag(index), nil)
+	}
+	_, err = message.Marshal()
+	assert.Equal(t, handshake.ErrTooManyEntries, err)
+}
+
+func TestMessageString(t *testing.T) {
+	message := handshake.NewMessage(handshake.TagSHLO)
+	message.Set(handshake.TagPAD, bytes.Repeat([]byte{0x2d}, 40))
+	assert.Equal(t, `SHLO{PAD: "`+string(bytes.Repeat([]byte{0x2d}, 32))+`"...(40 bytes)}`, message.String())
+}
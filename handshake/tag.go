@@ -0,0 +1,73 @@
+package handshake
+
+import "fmt"
+
+// Tag defines a four byte tag that identifies a message or a value in a message. On the wire, the
+// tag's characters appear in reading order, so the first character is the least significant byte.
+type Tag uint32
+
+// Definition of message tags.
+const (
+	TagCHLO Tag = 'C' | 'H'<<8 | 'L'<<16 | 'O'<<24 // client hello
+	TagSHLO Tag = 'S' | 'H'<<8 | 'L'<<16 | 'O'<<24 // server hello
+	TagREJ  Tag = 'R' | 'E'<<8 | 'J'<<16           // reject
+	TagSCFG Tag = 'S' | 'C'<<8 | 'F'<<16 | 'G'<<24 // server config
+	TagPRST Tag = 'P' | 'R'<<8 | 'S'<<16 | 'T'<<24 // public reset
+)
+
+// Definition of value tags.
+const (
+	TagVER  Tag = 'V' | 'E'<<8 | 'R'<<16            // version
+	TagSNI  Tag = 'S' | 'N'<<8 | 'I'<<16            // server name indication
+	TagPAD  Tag = 'P' | 'A'<<8 | 'D'<<16            // padding
+	TagPDMD Tag = 'P' | 'D'<<8 | 'M'<<16 | 'D'<<24  // proof demand
+	TagCCS  Tag = 'C' | 'C'<<8 | 'S'<<16            // common certificate sets
+	TagCCRT Tag = 'C' | 'C'<<8 | 'R'<<16 | 'T'<<24  // cached certificates
+	TagCRT  Tag = 'C' | 'R'<<8 | 'T'<<16 | 0xff<<24 // certificate chain
+	TagSTK  Tag = 'S' | 'T'<<8 | 'K'<<16            // source address token
+	TagSNO  Tag = 'S' | 'N'<<8 | 'O'<<16            // server nonce
+	TagPROF Tag = 'P' | 'R'<<8 | 'O'<<16 | 'F'<<24  // proof signature
+	TagSCID Tag = 'S' | 'C'<<8 | 'I'<<16 | 'D'<<24  // server config id
+	TagKEXS Tag = 'K' | 'E'<<8 | 'X'<<16 | 'S'<<24  // key exchange algorithms
+	TagAEAD Tag = 'A' | 'E'<<8 | 'A'<<16 | 'D'<<24  // authenticated encryption algorithms
+	TagPUBS Tag = 'P' | 'U'<<8 | 'B'<<16 | 'S'<<24  // public values
+	TagORBT Tag = 'O' | 'R'<<8 | 'B'<<16 | 'T'<<24  // orbit
+	TagEXPY Tag = 'E' | 'X'<<8 | 'P'<<16 | 'Y'<<24  // expiry
+	TagNONC Tag = 'N' | 'O'<<8 | 'N'<<16 | 'C'<<24  // client nonce
+	TagCETV Tag = 'C' | 'E'<<8 | 'T'<<16 | 'V'<<24  // client encrypted tag values
+	TagCFCW Tag = 'C' | 'F'<<8 | 'C'<<16 | 'W'<<24  // initial connection flow control window
+	TagSFCW Tag = 'S' | 'F'<<8 | 'C'<<16 | 'W'<<24  // initial stream flow control window
+	TagICSL Tag = 'I' | 'C'<<8 | 'S'<<16 | 'L'<<24  // idle connection state lifetime
+	TagMSPC Tag = 'M' | 'S'<<8 | 'P'<<16 | 'C'<<24  // max streams per connection
+	TagTCID Tag = 'T' | 'C'<<8 | 'I'<<16 | 'D'<<24  // truncated connection id
+	TagCOPT Tag = 'C' | 'O'<<8 | 'P'<<16 | 'T'<<24  // connection options
+	TagRREJ Tag = 'R' | 'R'<<8 | 'E'<<16 | 'J'<<24  // reject reasons
+	TagRNON Tag = 'R' | 'N'<<8 | 'O'<<16 | 'N'<<24  // public reset nonce proof
+	TagRSEQ Tag = 'R' | 'S'<<8 | 'E'<<16 | 'Q'<<24  // rejected packet number
+	TagCADR Tag = 'C' | 'A'<<8 | 'D'<<16 | 'R'<<24  // client address
+	TagX509 Tag = 'X' | '5'<<8 | '0'<<16 | '9'<<24  // x.509 certificate
+	TagC255 Tag = 'C' | '2'<<8 | '5'<<16 | '5'<<24  // curve25519 key exchange
+	TagAESG Tag = 'A' | 'E'<<8 | 'S'<<16 | 'G'<<24  // aes-gcm encryption
+	TagCC20 Tag = 'C' | 'C'<<8 | '2'<<16 | '0'<<24  // chacha20-poly1305 encryption
+	TagSTTL Tag = 'S' | 'T'<<8 | 'T'<<16 | 'L'<<24  // server config time to live
+	TagUAID Tag = 'U' | 'A'<<8 | 'I'<<16 | 'D'<<24  // user agent id
+)
+
+// String returns the tag's characters with trailing zero bytes removed. If the tag contains other
+// non-printable characters, they are escaped.
+func (t Tag) String() string {
+	b := []byte{byte(t), byte(t >> 8), byte(t >> 16), byte(t >> 24)}
+	for len(b) > 0 && b[len(b)-1] == 0x00 {
+		b = b[:len(b)-1]
+	}
+
+	s := ""
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			s += fmt.Sprintf("\\x%02x", c)
+			continue
+		}
+		s += string(rune(c))
+	}
+	return s
+}
@@ -0,0 +1,28 @@
+package handshake_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic/handshake"
+)
+
+func TestTagString(t *testing.T) {
+	testCases := []struct {
+		name string
+		tag  handshake.Tag
+		text string
+	}{
+		{"Full", handshake.TagCHLO, "CHLO"},
+		{"Short", handshake.TagREJ, "REJ"},
+		{"NonPrintable", handshake.TagCRT, `CRT\xff`},
+		{"Zero", handshake.Tag(0), ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.text, testCase.tag.String())
+		})
+	}
+}
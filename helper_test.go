@@ -2,12 +2,21 @@ package quic_test
 
 import (
 	"bufio"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"net"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic"
+	"github.com/simia-tech/go-quic/frame"
+	"github.com/simia-tech/go-quic/handshake"
+	"github.com/simia-tech/go-quic/packet"
 )
 
 func ListenUDP(tb testing.TB, localAddress string) *net.UDPConn {
@@ -29,10 +38,143 @@ func DialUDP(tb testing.TB, addr net.Addr) *net.UDPConn {
 func ReadLine(tb testing.TB, r io.Reader) string {
 	line, err := bufio.NewReader(r).ReadString('\n')
 	require.NoError(tb, err)
-	return line
+	return strings.TrimSuffix(line, "\n")
 }
 
 func WriteLine(tb testing.TB, w io.Writer, line string) {
 	_, err := fmt.Fprintf(w, "%s\n", line)
 	require.NoError(tb, err)
 }
+
+// WritePacket sends a client packet with the provided packet number and frames.
+func WritePacket(tb testing.TB, conn net.Conn, connectionID, packetNumber uint64, frames ...[]byte) {
+	payload := []byte{}
+	for _, f := range frames {
+		payload = append(payload, f...)
+	}
+
+	regular := packet.Regular(make([]byte, 1+8+4+1+len(payload)))
+	regular.AddConnectionID(connectionID)
+	regular.AddVersion(packet.VersionQ037)
+	regular.AddPacketNumber(packetNumber, 1)
+	regular.SetData(payload)
+
+	_, err := conn.Write(regular)
+	require.NoError(tb, err)
+}
+
+// ReadAcknowledge reads server packets until one with an acknowledge frame arrives and returns the
+// frame.
+func ReadAcknowledge(tb testing.TB, conn net.Conn) frame.Acknowledge {
+	require.NoError(tb, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buffer := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buffer)
+		require.NoError(tb, err)
+
+		p, err := packet.Parse(buffer[:n], packet.PerspectiveServer)
+		require.NoError(tb, err)
+		regular, ok := p.(packet.Regular)
+		if !ok {
+			continue
+		}
+		if ack, err := frame.ParseAcknowledge(regular.Data()); err == nil {
+			return ack
+		}
+	}
+}
+
+// ReadResetStream reads server packets until one with a reset stream frame arrives and returns the
+// frame.
+func ReadResetStream(tb testing.TB, conn net.Conn) frame.ResetStream {
+	require.NoError(tb, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buffer := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buffer)
+		require.NoError(tb, err)
+
+		p, err := packet.Parse(buffer[:n], packet.PerspectiveServer)
+		require.NoError(tb, err)
+		regular, ok := p.(packet.Regular)
+		if !ok {
+			continue
+		}
+		it := frame.NewIterator(regular.Data(), regular.PacketNumberLen())
+		for it.Next() {
+			if rs, ok := it.Frame().(frame.ResetStream); ok {
+				return rs
+			}
+		}
+	}
+}
+
+// StreamFrame returns a stream frame with the provided data and an explicit data length.
+func StreamFrame(streamID uint32, offset uint64, data string) []byte {
+	sf := frame.Stream(make([]byte, 1+4+8+2+len(data)))
+	sf.SetStreamID(streamID, 4)
+	sf.AddOffset(offset, 8)
+	sf.SetData([]byte(data))
+	return sf
+}
+
+// ClientHelloFrame returns a stream frame on the crypto stream that holds a client hello with the
+// provided version.
+func ClientHelloFrame(tb testing.TB, version quic.Version) []byte {
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, uint32(version))
+
+	message := handshake.NewMessage(handshake.TagCHLO)
+	message.Set(handshake.TagVER, value)
+	data, err := message.Marshal()
+	require.NoError(tb, err)
+	return StreamFrame(1, 0, string(data))
+}
+
+// ResetStreamFrame returns a reset stream frame with the provided final offset and error code.
+func ResetStreamFrame(streamID uint32, finalOffset uint64, errorCode uint32) []byte {
+	rs := frame.ResetStream(make([]byte, 1+4+8+4))
+	rs.SetStreamID(streamID)
+	rs.SetByteOffset(finalOffset)
+	rs.SetErrorCode(errorCode)
+	return rs
+}
+
+// LossyConn drops every nth packet written to the wrapped connection.
+type LossyConn struct {
+	net.Conn
+	dropper
+}
+
+func (c *LossyConn) Write(b []byte) (int, error) {
+	if c.drop() {
+		return len(b), nil
+	}
+	return c.Conn.Write(b)
+}
+
+// LossyPacketConn drops every nth packet written to the wrapped packet connection.
+type LossyPacketConn struct {
+	net.PacketConn
+	dropper
+}
+
+func (c *LossyPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if c.drop() {
+		return len(b), nil
+	}
+	return c.PacketConn.WriteTo(b, addr)
+}
+
+type dropper struct {
+	N int
+
+	mu    sync.Mutex
+	count int
+}
+
+func (d *dropper) drop() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.count++
+	return d.count%d.N == 0
+}
@@ -1,7 +1,273 @@
 package quic
 
-import "net"
+import (
+	"context"
+	"net"
+	"sync"
 
-func Listen(conn net.Conn, streamID int) (net.Listener, error) {
-	return nil, nil
+	"github.com/simia-tech/go-quic/packet"
+)
+
+// ListenSession returns a Listener that accepts quic sessions on the provided conn. Incoming packets
+// are routed to their sessions by the connection id, so many peers can be served over the same
+// conn. The listener takes ownership of conn and closes it when it gets closed.
+func ListenSession(conn net.PacketConn) (*Listener, error) {
+	l := &Listener{
+		conn:     conn,
+		sessions: make(map[uint64]*Session),
+		accept:   make(chan *Session, acceptQueueLen),
+		closed:   make(chan struct{}),
+		drained:  make(chan struct{}, 1),
+		versions: SupportedVersions(),
+	}
+	go l.readLoop()
+
+	return l, nil
+}
+
+// Listen returns a net.Listener that accepts quic sessions on the provided conn. Each accepted
+// net.Conn represents the stream with the provided id of a new session. Closing an accepted
+// net.Conn closes the whole session.
+func Listen(conn net.PacketConn, streamID int) (net.Listener, error) {
+	l, err := ListenSession(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &streamListener{Listener: l, streamID: uint32(streamID)}, nil
+}
+
+const acceptQueueLen = 16
+
+// Listener accepts quic sessions.
+type Listener struct {
+	conn net.PacketConn
+
+	mu           sync.Mutex
+	versions     []Version
+	sessions     map[uint64]*Session
+	shuttingDown bool
+	drained      chan struct{}
+	accept       chan *Session
+	closed       chan struct{}
+	closeErr     error
+	closeOnce    sync.Once
+}
+
+// Accept waits for and returns the next session.
+func (l *Listener) Accept() (*Session, error) {
+	select {
+	case s := <-l.accept:
+		return s, nil
+	case <-l.closed:
+		return nil, l.closeErr
+	}
+}
+
+// Close closes the listener, all its sessions and the underlying conn.
+func (l *Listener) Close() error {
+	l.closeWithError(ErrClosed)
+	return nil
+}
+
+// Shutdown gracefully shuts down the listener. New sessions are rejected and all sessions are told to
+// go away, so they get closed once their open streams are finished. When all sessions have been
+// closed, the listener is closed. If the context expires before, the listener is closed
+// immediately and the context's error is returned.
+func (l *Listener) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.shuttingDown = true
+	sessions := make([]*Session, 0, len(l.sessions))
+	for _, s := range l.sessions {
+		sessions = append(sessions, s)
+	}
+	l.mu.Unlock()
+
+	for _, s := range sessions {
+		s.GoAway()
+	}
+
+	for {
+		l.mu.Lock()
+		remaining := len(l.sessions)
+		l.mu.Unlock()
+		if remaining == 0 {
+			return l.Close()
+		}
+
+		select {
+		case <-l.drained:
+		case <-ctx.Done():
+			l.Close()
+			return ctx.Err()
+		}
+	}
+}
+
+// SetVersions sets the quic versions accepted by the listener. Clients that offer another version
+// get a version negotiation packet with these versions in the provided order, which should be the
+// descending order of preference. The default are all versions supported by this implementation.
+// If a version isn't supported, ErrUnsupportedVersion is returned. Sessions that have already been
+// accepted keep their version.
+func (l *Listener) SetVersions(versions []Version) error {
+	versions, err := checkVersions(versions)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.versions = versions
+	l.mu.Unlock()
+	return nil
+}
+
+// Addr returns the listener's network address.
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// streamListener implements a net.Listener that returns a single stream of each accepted session.
+type streamListener struct {
+	*Listener
+	streamID uint32
+}
+
+func (sl *streamListener) Accept() (net.Conn, error) {
+	s, err := sl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &sessionStream{Stream: s.openStreamWithID(sl.streamID)}, nil
+}
+
+func (l *Listener) readLoop() {
+	buffer := make([]byte, maxReceivePacketSize)
+	for {
+		n, addr, err := l.conn.ReadFrom(buffer)
+		if err != nil {
+			l.closeWithError(err)
+			return
+		}
+		l.handlePacket(buffer[:n], addr)
+	}
+}
+
+func (l *Listener) handlePacket(b []byte, addr net.Addr) {
+	p, err := packet.Parse(b, packet.PerspectiveClient)
+	if err != nil {
+		return
+	}
+	regular := p.(packet.Regular)
+	if packet.Header(regular).Flags()&packet.FlagConnectionID == 0 {
+		// Without a connection id, the packet can't be assigned to a session.
+		return
+	}
+	connectionID := regular.ConnectionID()
+
+	l.mu.Lock()
+	s, ok := l.sessions[connectionID]
+	versions := l.versions
+	l.mu.Unlock()
+
+	if !ok {
+		if packet.Header(regular).Flags()&packet.FlagVersion == 0 {
+			l.sendPublicReset(connectionID, regular.PacketNumber(), addr)
+			return
+		}
+		if !containsVersion(versions, regular.Version()) {
+			l.sendVersionNegotiation(connectionID, versions, addr)
+			return
+		}
+
+		var accepted bool
+		s, accepted = l.newSession(connectionID, regular.Version(), addr)
+		if !accepted {
+			l.sendPublicReset(connectionID, regular.PacketNumber(), addr)
+			return
+		}
+		if s == nil {
+			return
+		}
+	}
+
+	s.handleRegular(regular, addr)
+}
+
+// newSession creates a session for the connection with the provided id and queues it for Accept.
+// If the listener is shutting down, false is returned. If the accept queue is full, the session is
+// closed and nil is returned.
+func (l *Listener) newSession(connectionID uint64, version Version, addr net.Addr) (*Session, bool) {
+	t := &packetConnTransport{
+		conn:       l.conn,
+		remoteAddr: addr,
+		onClose: func() {
+			l.mu.Lock()
+			delete(l.sessions, connectionID)
+			l.mu.Unlock()
+			signal(l.drained)
+		},
+	}
+
+	// The session is added in the same critical section that checks for a shutdown, so Shutdown
+	// either sees the session and tells it to go away or the session isn't created.
+	l.mu.Lock()
+	if l.shuttingDown {
+		l.mu.Unlock()
+		return nil, false
+	}
+	s := newSession(packet.PerspectiveServer, connectionID, t)
+	s.version = version
+	s.versions = l.versions
+	l.sessions[connectionID] = s
+	l.mu.Unlock()
+
+	select {
+	case l.accept <- s:
+	default:
+		// The accept queue is full, so the connection is dropped.
+		s.Close()
+		return nil, true
+	}
+
+	return s, true
+}
+
+// sendPublicReset tells the peer that the connection with the provided id is unknown. Since the
+// listener has no state for the connection, the rejected packet number is sent as it has been
+// received.
+func (l *Listener) sendPublicReset(connectionID, rejectedPacketNumber uint64, addr net.Addr) {
+	nonceProof, err := randomUint64()
+	if err != nil {
+		return
+	}
+	clientAddress, _ := addr.(*net.UDPAddr)
+
+	pr := packet.PublicReset(make([]byte, packet.PublicResetLen(clientAddress)))
+	pr.SetConnectionID(connectionID)
+	pr.SetMessage(nonceProof, rejectedPacketNumber, clientAddress)
+	l.conn.WriteTo(pr, addr)
+}
+
+func (l *Listener) sendVersionNegotiation(connectionID uint64, versions []Version, addr net.Addr) {
+	vn := packet.VersionNegotiation(make([]byte, 9+4*len(versions)))
+	vn.SetConnectionID(connectionID)
+	vn.SetVersions(versions)
+	l.conn.WriteTo(vn, addr)
+}
+
+func (l *Listener) closeWithError(err error) {
+	l.closeOnce.Do(func() {
+		l.mu.Lock()
+		sessions := make([]*Session, 0, len(l.sessions))
+		for _, s := range l.sessions {
+			sessions = append(sessions, s)
+		}
+		l.closeErr = err
+		l.mu.Unlock()
+
+		close(l.closed)
+		for _, s := range sessions {
+			s.closeWithError(err)
+		}
+		l.conn.Close()
+	})
 }
@@ -0,0 +1,184 @@
+package packet
+
+import (
+	"fmt"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+// Builder builds a regular packet of a limited size. The header fields can be set in any order,
+// since the header is written when the packet is finished. Frames are appended to the payload as
+// long as they fit into the packet. It's not safe for concurrent use.
+type Builder struct {
+	maxSize int
+	buffer  []byte
+
+	connectionID    uint64
+	hasConnectionID bool
+	version         Version
+	hasVersion      bool
+	nonce           []byte
+	packetNumber    uint64
+	packetNumberLen int
+
+	payloadLen int
+	full       bool
+}
+
+// NewBuilder returns a builder for a packet of at most maxSize bytes.
+func NewBuilder(maxSize int) *Builder {
+	return &Builder{
+		maxSize: maxSize,
+		buffer:  make([]byte, MaxHeaderSize+maxSize),
+	}
+}
+
+// SetConnectionID sets the connection id of the packet.
+func (b *Builder) SetConnectionID(value uint64) {
+	b.connectionID = value
+	b.hasConnectionID = true
+}
+
+// SetVersion sets the quic version of the packet.
+func (b *Builder) SetVersion(value Version) {
+	b.version = value
+	b.hasVersion = true
+}
+
+// SetNonce sets the diversification nonce of the packet. The nonce has to be NonceLen bytes long,
+// otherwise SetNonce panics.
+func (b *Builder) SetNonce(value []byte) {
+	if len(value) != NonceLen {
+		panic(fmt.Sprintf("expected nonce to have %d bytes, got %d", NonceLen, len(value)))
+	}
+	b.nonce = value
+}
+
+// SetPacketNumber sets the packet number with the provided length in bytes. The length has to be
+// 1, 2, 4 or 6. Other lengths will cause a panic.
+func (b *Builder) SetPacketNumber(value uint64, length int) {
+	if length != 1 && length != 2 && length != 4 && length != 6 {
+		panic(fmt.Sprintf("cannot set packet number with length %d", length))
+	}
+	b.packetNumber = value
+	b.packetNumberLen = length
+}
+
+// Remaining returns the number of bytes that can still be appended to the payload. Since the header
+// counts towards the packet size, the header fields should be set before frames are appended.
+func (b *Builder) Remaining() int {
+	if b.full {
+		return 0
+	}
+	if remaining := b.maxSize - b.headerLen() - b.payloadLen; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// AppendFrame appends the provided frame to the payload. If the frame doesn't fit into the packet,
+// nothing is appended and false is returned. Stream frames without data length field extend to
+// the end of the packet and have to be appended with AppendStreamFrame.
+func (b *Builder) AppendFrame(f []byte) bool {
+	if len(f) > b.Remaining() {
+		return false
+	}
+	copy(b.payload(len(f)), f)
+	b.payloadLen += len(f)
+	return true
+}
+
+// AppendStreamFrame appends a stream frame with as much of the provided data as fits into the
+// packet and returns the number of appended data bytes. The fin flag is only set, if all data has
+// been appended. If the frame fills the packet, the data length field is omitted and no further
+// frames can be appended. If not even the frame header and one data byte fit, nothing is appended
+// and false is returned.
+func (b *Builder) AppendStreamFrame(streamID uint32, offset uint64, data []byte, fin bool) (int, bool) {
+	streamIDLen, offsetLen := frame.MinStreamIDLen(streamID), frame.MinOffsetLen(offset)
+	headerLen := 1 + streamIDLen + offsetLen
+	remaining := b.Remaining()
+	if remaining < headerLen || (len(data) > 0 && remaining == headerLen) {
+		return 0, false
+	}
+
+	// The data length field is only omitted, if no other frame would fit behind this one.
+	explicit := headerLen+2+len(data) < remaining
+
+	n := len(data)
+	l := headerLen + n
+	if explicit {
+		l += 2
+	} else if n > remaining-headerLen {
+		n = remaining - headerLen
+		l = remaining
+	}
+
+	sf := frame.Stream(b.payload(l))
+	for index := range sf {
+		sf[index] = 0
+	}
+	sf.SetStreamID(streamID, streamIDLen)
+	if offsetLen > 0 {
+		sf.AddOffset(offset, offsetLen)
+	}
+	if fin && n == len(data) {
+		sf.SetFin()
+	}
+	if explicit {
+		sf.SetData(data)
+	} else {
+		sf.SetImplicitData(data[:n])
+		b.full = true
+	}
+	b.payloadLen += l
+
+	return n, true
+}
+
+// Packet writes the header and returns the packet. The packet refers to the builder's buffer, so
+// it's only valid until the builder is reset. If no packet number has been set, Packet panics.
+func (b *Builder) Packet() Regular {
+	if b.packetNumberLen == 0 {
+		panic("cannot build packet without packet number")
+	}
+
+	start := MaxHeaderSize - b.headerLen()
+	r := Regular(b.buffer[start : MaxHeaderSize+b.payloadLen])
+	r[0] = 0x00
+	if b.hasConnectionID {
+		r.AddConnectionID(b.connectionID)
+	}
+	if b.hasVersion {
+		r.AddVersion(b.version)
+	}
+	if b.nonce != nil {
+		r.AddNonce(b.nonce)
+	}
+	r.AddPacketNumber(b.packetNumber, b.packetNumberLen)
+	return r
+}
+
+// Reset clears the header fields and the payload, so the builder can be reused for the next packet.
+func (b *Builder) Reset() {
+	*b = Builder{maxSize: b.maxSize, buffer: b.buffer}
+}
+
+// payload returns the next l bytes of the payload buffer.
+func (b *Builder) payload(l int) []byte {
+	offset := MaxHeaderSize + b.payloadLen
+	return b.buffer[offset : offset+l]
+}
+
+func (b *Builder) headerLen() int {
+	l := 1 + b.packetNumberLen
+	if b.hasConnectionID {
+		l += 8
+	}
+	if b.hasVersion {
+		l += 4
+	}
+	if b.nonce != nil {
+		l += NonceLen
+	}
+	return l
+}
@@ -0,0 +1,141 @@
+package packet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/frame"
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestBuilder(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		maxSize int
+		frames  [][]byte
+		data    []byte
+		fin     bool
+
+		appended bool
+		n        int
+		bytes    []byte
+	}{
+		{"Frame", 20, [][]byte{{0x07}}, nil, false, false, 0,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03, 0x07}},
+		{"FrameTooLarge", 16, [][]byte{{0x07}, {0x05, 0x01, 0x00, 0x00, 0x00}}, nil, false, false, 0,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03, 0x07}},
+		{"StreamExplicit", 30, [][]byte{{0x07}}, []byte{0x04, 0x05}, true, true, 2,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03, 0x07,
+				0xe4, 0x05, 0x00, 0x04, 0x02, 0x00, 0x04, 0x05}},
+		{"StreamImplicit", 20, nil, []byte{0x04, 0x05}, true, true, 2,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03,
+				0xc4, 0x05, 0x00, 0x04, 0x04, 0x05}},
+		{"StreamSplit", 19, nil, []byte{0x04, 0x05, 0x06}, true, true, 1,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03,
+				0x84, 0x05, 0x00, 0x04, 0x04}},
+		{"StreamFin", 30, nil, []byte{}, true, true, 0,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03,
+				0xe4, 0x05, 0x00, 0x04, 0x00, 0x00}},
+		{"StreamFinImplicit", 20, nil, []byte{}, true, true, 0,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03,
+				0xc4, 0x05, 0x00, 0x04}},
+		{"StreamTooLarge", 18, nil, []byte{0x04}, false, false, 0,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03}},
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				builder := packet.NewBuilder(testCase.maxSize)
+				builder.SetPacketNumber(3, 1)
+				builder.SetVersion(packet.VersionQ039)
+				builder.SetConnectionID(1)
+
+				for index, f := range testCase.frames {
+					builder.AppendFrame(f)
+					if index == 0 {
+						require.Equal(t, testCase.maxSize-len(testCase.bytes[:14])-len(f), builder.Remaining())
+					}
+				}
+				if testCase.data != nil {
+					n, appended := builder.AppendStreamFrame(5, 1024, testCase.data, testCase.fin)
+					assert.Equal(t, testCase.appended, appended)
+					assert.Equal(t, testCase.n, n)
+				}
+
+				assert.Equal(t, testCase.bytes, []byte(builder.Packet()))
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				regular, err := packet.ParseRegular(testCase.bytes)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(1), regular.ConnectionID())
+				assert.Equal(t, packet.VersionQ039, regular.Version())
+				assert.Equal(t, uint64(3), regular.PacketNumber())
+
+				it := frame.NewIterator(regular.Data(), 1)
+				for it.Next() {
+					if stream, ok := it.Frame().(frame.Stream); ok {
+						assert.Equal(t, testCase.data[:testCase.n], stream.Data())
+						assert.Equal(t, testCase.fin && testCase.n == len(testCase.data), stream.Fin())
+					}
+				}
+				require.NoError(t, it.Err())
+			})
+		}
+	})
+}
+
+func TestBuilderRemaining(t *testing.T) {
+	builder := packet.NewBuilder(100)
+	builder.SetPacketNumber(1, 2)
+	assert.Equal(t, 97, builder.Remaining())
+
+	builder.SetConnectionID(1)
+	builder.SetNonce(make([]byte, packet.NonceLen))
+	assert.Equal(t, 57, builder.Remaining())
+
+	n, appended := builder.AppendStreamFrame(3, 0, make([]byte, 100), false)
+	assert.True(t, appended)
+	assert.Equal(t, 55, n)
+	assert.Equal(t, 0, builder.Remaining())
+	assert.False(t, builder.AppendFrame([]byte{0x07}))
+	assert.Len(t, builder.Packet(), 100)
+
+	builder.Reset()
+	builder.SetPacketNumber(2, 1)
+	assert.Equal(t, 98, builder.Remaining())
+	assert.Equal(t, []byte{0x00, 0x02}, []byte(builder.Packet()))
+}
+
+func TestBuilderStreamFinFollowedByFrame(t *testing.T) {
+	builder := packet.NewBuilder(100)
+	builder.SetPacketNumber(1, 1)
+
+	n, appended := builder.AppendStreamFrame(3, 0, nil, true)
+	assert.True(t, appended)
+	assert.Equal(t, 0, n)
+	assert.True(t, builder.AppendFrame([]byte{0x07}))
+	assert.Equal(t, []byte{0x00, 0x01, 0xe0, 0x03, 0x00, 0x00, 0x07}, []byte(builder.Packet()))
+}
+
+func TestBuilderAllocations(t *testing.T) {
+	builder := packet.NewBuilder(100)
+	data := []byte{0x01, 0x02, 0x03}
+
+	allocations := testing.AllocsPerRun(100, func() {
+		builder.Reset()
+		builder.SetConnectionID(1)
+		builder.SetPacketNumber(2, 2)
+		builder.AppendStreamFrame(3, 1024, data, false)
+		builder.Packet()
+	})
+	assert.Equal(t, 0.0, allocations)
+}
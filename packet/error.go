@@ -0,0 +1,12 @@
+package packet
+
+import "errors"
+
+// Errors returned by the parse and open functions.
+var (
+	ErrTruncated      = errors.New("packet: truncated")
+	ErrInvalidFlags   = errors.New("packet: invalid flags")
+	ErrInvalidMessage = errors.New("packet: invalid message")
+	ErrInvalidVersion = errors.New("packet: invalid version")
+	ErrAuthentication = errors.New("packet: authentication failed")
+)
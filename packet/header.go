@@ -13,18 +13,38 @@ const (
 	FlagNonce        = 0x04
 	FlagConnectionID = 0x08
 
+	ReservedMask = 0xc0
+
 	PacketNumberMask = 0x30
 	PacketNumberLen6 = 0x30
 	PacketNumberLen4 = 0x20
 	PacketNumberLen2 = 0x10
 	PacketNumberLen1 = 0x00
 
-	MaxHeaderSize = 19
+	NonceLen = 32
+
+	MaxHeaderSize = 51
 )
 
 // Header defines the packet header type.
 type Header []byte
 
+// ParseHeader validates the public header at the beginning of the provided buffer. If the buffer is
+// too short, ErrTruncated is returned. If reserved flags are set, ErrInvalidFlags is returned.
+func ParseHeader(b []byte) (Header, error) {
+	if len(b) < 1 {
+		return nil, ErrTruncated
+	}
+	h := Header(b)
+	if h[0]&ReservedMask != 0x00 {
+		return nil, ErrInvalidFlags
+	}
+	if len(h) < h.Len() {
+		return nil, ErrTruncated
+	}
+	return h, nil
+}
+
 // SetFlags sets public header flags.
 func (h Header) SetFlags(flag uint8) {
 	h.ensureLen(1)
@@ -37,8 +57,7 @@ func (h Header) Flags() uint8 {
 	return h[0]
 }
 
-// AddConnectionID adds the connection id and sets the corresponding header flags. The value has to be
-// uint8, uint16, uint32 or uint64. Values of other types will cause a panic.
+// AddConnectionID adds the connection id and sets the corresponding header flags.
 func (h Header) AddConnectionID(value uint64) {
 	h.ensureLen(9)
 	h.SetFlags(FlagConnectionID)
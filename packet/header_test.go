@@ -5,6 +5,7 @@ import (
 
 	"github.com/simia-tech/go-quic/packet"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHeader(t *testing.T) {
@@ -51,4 +52,17 @@ func TestHeader(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				header, err := packet.ParseHeader(testCase.bytes)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.flags, header.Flags())
+
+				_, err = packet.ParseHeader(testCase.bytes[:len(testCase.bytes)-1])
+				assert.Equal(t, packet.ErrTruncated, err)
+			})
+		}
+	})
 }
@@ -0,0 +1,78 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// NullHashLen defines the length of the integrity hash that the null encryption puts in front of
+// the plaintext.
+const NullHashLen = 12
+
+// NullEncryption protects packets before keys have been established. It doesn't encrypt the
+// payload, but puts a hash in front of it, that is the FNV-1a 128 bit hash of the associated data
+// and the plaintext truncated to its lower 96 bits. Since version Q037, the sender's perspective
+// is hashed as well. NullEncryption implements Sealer and Opener.
+type NullEncryption struct {
+	label []byte
+}
+
+var (
+	_ Sealer = &NullEncryption{}
+	_ Opener = &NullEncryption{}
+)
+
+// NewNullEncryption returns the null encryption for packets of the provided version that are sent
+// by the provided perspective.
+func NewNullEncryption(sentBy Perspective, version Version) *NullEncryption {
+	ne := &NullEncryption{}
+	if version >= VersionQ037 {
+		switch sentBy {
+		case PerspectiveClient:
+			ne.label = []byte("Client")
+		case PerspectiveServer:
+			ne.label = []byte("Server")
+		}
+	}
+	return ne
+}
+
+// Seal appends the hash and the plaintext to dst. The packet number is ignored.
+func (ne *NullEncryption) Seal(dst []byte, packetNumber uint64, plaintext, associatedData []byte) []byte {
+	dst = append(dst, ne.hash(associatedData, plaintext)...)
+	return append(dst, plaintext...)
+}
+
+// Open verifies the hash at the beginning of the ciphertext and appends the plaintext to dst. The
+// packet number is ignored.
+func (ne *NullEncryption) Open(dst []byte, packetNumber uint64, ciphertext, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < NullHashLen {
+		return nil, ErrTruncated
+	}
+	plaintext := ciphertext[NullHashLen:]
+	if !bytes.Equal(ciphertext[:NullHashLen], ne.hash(associatedData, plaintext)) {
+		return nil, ErrAuthentication
+	}
+	return append(dst, plaintext...), nil
+}
+
+// Overhead returns the length of the hash.
+func (ne *NullEncryption) Overhead() int {
+	return NullHashLen
+}
+
+// hash returns the lower 96 bits of the hash as a little endian 64 bit integer followed by a
+// little endian 32 bit integer.
+func (ne *NullEncryption) hash(associatedData, plaintext []byte) []byte {
+	h := fnv.New128a()
+	h.Write(associatedData)
+	h.Write(plaintext)
+	h.Write(ne.label)
+	sum := h.Sum(nil)
+
+	b := make([]byte, NullHashLen)
+	binary.LittleEndian.PutUint64(b, binary.BigEndian.Uint64(sum[8:]))
+	binary.LittleEndian.PutUint32(b[8:], binary.BigEndian.Uint32(sum[4:]))
+	return b
+}
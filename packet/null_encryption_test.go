@@ -0,0 +1,79 @@
+package packet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestNullEncryption(t *testing.T) {
+	associatedData := []byte("All human beings are born free and equal in dignity and rights.")
+	plaintext := []byte("They are endowed with reason and conscience and should act towards one " +
+		"another in a spirit of brotherhood.")
+
+	testCases := []struct {
+		name           string
+		sentBy         packet.Perspective
+		version        packet.Version
+		associatedData []byte
+		plaintext      []byte
+		hash           []byte
+	}{
+		{"Q036", packet.PerspectiveClient, packet.VersionQ036, associatedData, plaintext,
+			[]byte{0x98, 0x9b, 0x33, 0x3f, 0xe8, 0xde, 0x32, 0x5c, 0xa6, 0x7f, 0x9c, 0xf7}},
+		{"Q039Client", packet.PerspectiveClient, packet.VersionQ039, associatedData, plaintext,
+			[]byte{0x9f, 0xc0, 0xf1, 0xb6, 0xbb, 0x00, 0x26, 0x01, 0x09, 0xc1, 0x1a, 0x58}},
+		{"Q039Server", packet.PerspectiveServer, packet.VersionQ039, associatedData, plaintext,
+			[]byte{0x3b, 0x71, 0x6f, 0x43, 0x79, 0x41, 0xef, 0x00, 0x09, 0xc1, 0x1a, 0x72}},
+		{"Empty", packet.PerspectiveClient, packet.VersionQ035, nil, nil,
+			[]byte{0x8d, 0xc5, 0x95, 0x62, 0x75, 0x21, 0xb8, 0x62, 0x42, 0x01, 0xbb, 0x07}},
+	}
+
+	t.Run("Seal", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				ne := packet.NewNullEncryption(testCase.sentBy, testCase.version)
+				ciphertext := ne.Seal([]byte{0xff}, 1, testCase.plaintext, testCase.associatedData)
+
+				assert.Equal(t, packet.NullHashLen, ne.Overhead())
+				assert.Equal(t, append(append([]byte{0xff}, testCase.hash...), testCase.plaintext...), ciphertext)
+			})
+		}
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				ne := packet.NewNullEncryption(testCase.sentBy, testCase.version)
+				ciphertext := append(append([]byte{}, testCase.hash...), testCase.plaintext...)
+
+				opened, err := ne.Open(nil, 1, ciphertext, testCase.associatedData)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.plaintext, opened)
+
+				_, err = ne.Open(nil, 1, ciphertext, append(testCase.associatedData, 0x00))
+				assert.Equal(t, packet.ErrAuthentication, err)
+
+				_, err = ne.Open(nil, 1, ciphertext[:packet.NullHashLen-1], testCase.associatedData)
+				assert.Equal(t, packet.ErrTruncated, err)
+			})
+		}
+	})
+}
+
+func TestNullEncryptionPerspective(t *testing.T) {
+	client := packet.NewNullEncryption(packet.PerspectiveClient, packet.VersionQ039)
+	server := packet.NewNullEncryption(packet.PerspectiveServer, packet.VersionQ039)
+	ciphertext := client.Seal(nil, 1, []byte("payload"), []byte("header"))
+
+	// The peer has to open the packets with the sender's perspective.
+	_, err := server.Open(nil, 1, ciphertext, []byte("header"))
+	assert.Equal(t, packet.ErrAuthentication, err)
+
+	plaintext, err := client.Open(nil, 1, ciphertext, []byte("header"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), plaintext)
+}
@@ -0,0 +1,67 @@
+package packet
+
+// Perspective defines whether a packet has been sent by a client or a server.
+type Perspective int
+
+// Definition of perspectives.
+const (
+	PerspectiveClient Perspective = iota
+	PerspectiveServer
+)
+
+func (p Perspective) String() string {
+	switch p {
+	case PerspectiveClient:
+		return "client"
+	case PerspectiveServer:
+		return "server"
+	}
+	return "unknown"
+}
+
+// Packet defines the common interface of Regular, VersionNegotiation and PublicReset.
+type Packet interface {
+	ConnectionID() uint64
+	Len() int
+}
+
+// Parse classifies the packet in the provided buffer and returns it as a Regular,
+// VersionNegotiation or PublicReset. Since the version flag means "version negotiation" in packets
+// sent by a server and "version present" in packets sent by a client, the perspective of the
+// sender has to be provided. The returned packet has been validated by the corresponding parse
+// function.
+func Parse(b []byte, sentBy Perspective) (Packet, error) {
+	header, err := ParseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := header.Flags()
+	switch sentBy {
+	case PerspectiveServer:
+		if flags&FlagPublicReset != 0x00 {
+			pr, err := ParsePublicReset(b)
+			if err != nil {
+				return nil, err
+			}
+			return pr, nil
+		}
+		if flags&FlagVersion != 0x00 {
+			vn, err := ParseVersionNegotiation(b)
+			if err != nil {
+				return nil, err
+			}
+			return vn, nil
+		}
+	case PerspectiveClient:
+		if flags&(FlagPublicReset|FlagNonce) != 0x00 {
+			return nil, ErrInvalidFlags
+		}
+	}
+
+	r, err := ParseRegular(b)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,44 @@
+package packet
+
+// MaxPacketNumber defines the largest packet number that can be encoded in a packet.
+const MaxPacketNumber = 1<<48 - 1
+
+// packetNumberLens lists the packet number lengths in bytes in ascending order.
+var packetNumberLens = []int{1, 2, 4, 6}
+
+// PacketNumberLen returns the shortest length in bytes (1, 2, 4 or 6) that allows the peer to
+// reconstruct the provided packet number, given that the packet with the number largestAcked has
+// been acknowledged. Since packet numbers start at 1, a largestAcked of zero means that no packet
+// has been acknowledged yet.
+func PacketNumberLen(packetNumber, largestAcked uint64) int {
+	unacked := packetNumber - largestAcked
+	for _, l := range packetNumberLens[:len(packetNumberLens)-1] {
+		if unacked < 1<<uint(8*l-1) {
+			return l
+		}
+	}
+	return packetNumberLens[len(packetNumberLens)-1]
+}
+
+// TruncatePacketNumber returns the lowest length bytes of the provided packet number.
+func TruncatePacketNumber(packetNumber uint64, length int) uint64 {
+	return packetNumber & (1<<uint(8*length) - 1)
+}
+
+// ExpandPacketNumber reconstructs the full packet number from the truncated value of the provided
+// length. The result is the packet number closest to the one following largestReceived.
+func ExpandPacketNumber(truncated uint64, length int, largestReceived uint64) uint64 {
+	expected := largestReceived + 1
+	window := uint64(1) << uint(8*length)
+	halfWindow := window / 2
+	mask := window - 1
+
+	candidate := (expected &^ mask) | (truncated & mask)
+	if candidate+halfWindow <= expected && candidate+window <= MaxPacketNumber {
+		return candidate + window
+	}
+	if candidate > expected+halfWindow && candidate >= window {
+		return candidate - window
+	}
+	return candidate
+}
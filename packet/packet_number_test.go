@@ -0,0 +1,141 @@
+package packet_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestPacketNumberLen(t *testing.T) {
+	testCases := []struct {
+		packetNumber uint64
+		largestAcked uint64
+		expectLen    int
+	}{
+		{1, 0, 1},
+		{127, 0, 1},
+		{128, 0, 2},
+		{1127, 1000, 1},
+		{1128, 1000, 2},
+		{1200, 1000, 2},
+		{1 << 15, 1, 2},
+		{1<<15 + 1, 1, 4},
+		{1 << 31, 1, 4},
+		{1<<31 + 1, 1, 6},
+		{packet.MaxPacketNumber, 0, 6},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%d-%d", testCase.packetNumber, testCase.largestAcked), func(t *testing.T) {
+			assert.Equal(t, testCase.expectLen, packet.PacketNumberLen(testCase.packetNumber, testCase.largestAcked))
+		})
+	}
+}
+
+func TestExpandPacketNumber(t *testing.T) {
+	testCases := []struct {
+		truncated       uint64
+		length          int
+		largestReceived uint64
+		expectNumber    uint64
+	}{
+		{0x01, 1, 0, 0x01},
+		{0x00, 1, 0xff, 0x100},
+		{0xff, 1, 0x100, 0xff},
+		{0x01, 1, 0x1fe, 0x201},
+		{0x7f, 1, 0x1ff, 0x27f},
+		{0x80, 1, 0x1ff, 0x280},
+		{0x81, 1, 0x1ff, 0x181},
+		{0x9b32, 2, 0xa82f30ea, 0xa82f9b32},
+		{0xffff, 2, 0x10000, 0xffff},
+		{0x0000, 4, 0xffffffff, 0x100000000},
+		{0xffffffffffff, 6, 0xfffffffffffe, 0xffffffffffff},
+		{0xff, 1, packet.MaxPacketNumber - 1, packet.MaxPacketNumber},
+		{0x00, 1, packet.MaxPacketNumber - 1, packet.MaxPacketNumber - 0xff},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(fmt.Sprintf("%x-%d-%x", testCase.truncated, testCase.length, testCase.largestReceived), func(t *testing.T) {
+			assert.Equal(t, testCase.expectNumber,
+				packet.ExpandPacketNumber(testCase.truncated, testCase.length, testCase.largestReceived))
+		})
+	}
+}
+
+func TestPacketNumberRoundTrip(t *testing.T) {
+	for _, length := range []int{1, 2, 4, 6} {
+		window := uint64(1) << uint(8*length)
+		halfWindow := window / 2
+
+		largests := []uint64{0, 1, halfWindow - 1, halfWindow, window - 1, window, window + 1, 3*window - 1,
+			packet.MaxPacketNumber - window, packet.MaxPacketNumber - halfWindow, packet.MaxPacketNumber - 1}
+		if length == 1 {
+			largests = nil
+			for largest := uint64(0); largest < 4*window; largest++ {
+				largests = append(largests, largest)
+			}
+		}
+
+		for _, largest := range largests {
+			if largest >= packet.MaxPacketNumber {
+				continue
+			}
+			// Every packet number within half a window around the expected one must survive the
+			// truncation.
+			from := largest + 1 - halfWindow + 1
+			if largest+1 < halfWindow {
+				from = 0
+			}
+			to := largest + 1 + halfWindow
+			if to > packet.MaxPacketNumber {
+				to = packet.MaxPacketNumber
+			}
+			for _, packetNumber := range sample(from, to, length) {
+				truncated := packet.TruncatePacketNumber(packetNumber, length)
+				if !assert.Equal(t, packetNumber, packet.ExpandPacketNumber(truncated, length, largest),
+					"length %d, largest received %x, packet number %x", length, largest, packetNumber) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestPacketNumberLenRoundTrip(t *testing.T) {
+	for largestAcked := uint64(0); largestAcked < 600; largestAcked += 7 {
+		for packetNumber := largestAcked + 1; packetNumber < largestAcked+70000; packetNumber += 13 {
+			length := packet.PacketNumberLen(packetNumber, largestAcked)
+			truncated := packet.TruncatePacketNumber(packetNumber, length)
+
+			// The receiver might have seen any packet between the acknowledged one and the new one.
+			for _, largestReceived := range []uint64{largestAcked, (largestAcked + packetNumber) / 2, packetNumber - 1} {
+				if !assert.Equal(t, packetNumber, packet.ExpandPacketNumber(truncated, length, largestReceived),
+					"largest acked %d, largest received %d, packet number %d", largestAcked, largestReceived, packetNumber) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// sample returns all values from the provided range for short packet numbers and the values close
+// to the range boundaries for longer ones.
+func sample(from, to uint64, length int) []uint64 {
+	values := []uint64{}
+	if length <= 2 || to-from < 1024 {
+		for value := from; value <= to; value++ {
+			values = append(values, value)
+		}
+		return values
+	}
+	for value := from; value < from+512; value++ {
+		values = append(values, value)
+	}
+	for value := to - 511; value <= to; value++ {
+		values = append(values, value)
+	}
+	return values
+}
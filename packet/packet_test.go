@@ -0,0 +1,66 @@
+package packet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestParse(t *testing.T) {
+	publicReset := []byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		'P', 'R', 'S', 'T', 0x02, 0x00, 0x00, 0x00,
+		'R', 'N', 'O', 'N', 0x08, 0x00, 0x00, 0x00,
+		'R', 'S', 'E', 'Q', 0x10, 0x00, 0x00, 0x00,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	testCases := []struct {
+		name   string
+		sentBy packet.Perspective
+		bytes  []byte
+
+		expectPacket packet.Packet
+		expectErr    error
+	}{
+		{"ClientRegular", packet.PerspectiveClient,
+			[]byte{0x08, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03},
+			packet.Regular{0x08, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03}, nil},
+		{"ClientRegularVersion", packet.PerspectiveClient,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03},
+			packet.Regular{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03}, nil},
+		{"ClientPublicReset", packet.PerspectiveClient,
+			publicReset,
+			nil, packet.ErrInvalidFlags},
+		{"ClientNonce", packet.PerspectiveClient,
+			[]byte{0x0c, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			nil, packet.ErrInvalidFlags},
+		{"ServerRegular", packet.PerspectiveServer,
+			[]byte{0x08, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03},
+			packet.Regular{0x08, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03}, nil},
+		{"ServerVersionNegotiation", packet.PerspectiveServer,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9'},
+			packet.VersionNegotiation{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9'}, nil},
+		{"ServerPublicReset", packet.PerspectiveServer,
+			publicReset,
+			packet.PublicReset(publicReset), nil},
+		{"ServerPublicResetWithoutMessage", packet.PerspectiveServer,
+			[]byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			nil, packet.ErrTruncated},
+		{"ServerVersionNegotiationTruncated", packet.PerspectiveServer,
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00},
+			nil, packet.ErrTruncated},
+		{"Truncated", packet.PerspectiveServer,
+			[]byte{0x08, 0x01, 0x00},
+			nil, packet.ErrTruncated},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p, err := packet.Parse(testCase.bytes, testCase.sentBy)
+			assert.Equal(t, testCase.expectErr, err)
+			assert.Equal(t, testCase.expectPacket, p)
+		})
+	}
+}
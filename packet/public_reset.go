@@ -1,8 +1,63 @@
 package packet
 
-// PublicReset defines the public reset packet type.
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/simia-tech/go-quic/handshake"
+)
+
+// Address families of the client address in the public reset message.
+const (
+	addressFamilyIPv4 = 2
+	addressFamilyIPv6 = 10
+)
+
+// PublicReset defines the public reset packet type. The header is followed by a PRST handshake
+// message, that holds the nonce proof, the rejected packet number and optionally the client
+// address.
 type PublicReset []byte
 
+// PublicResetLen returns the length of a public reset packet with the provided client address. If
+// the client address is nil, the message has no client address.
+func PublicResetLen(clientAddress *net.UDPAddr) int {
+	return 1 + 8 + newPublicResetMessage(0, 0, clientAddress).Len()
+}
+
+// ParsePublicReset validates the public reset packet in the provided buffer. If the buffer is too
+// short, ErrTruncated is returned. If the flags don't describe a public reset packet,
+// ErrInvalidFlags is returned. If the message isn't a valid PRST message, ErrInvalidMessage is
+// returned. The accessors of the returned packet don't panic.
+func ParsePublicReset(b []byte) (PublicReset, error) {
+	header, err := ParseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if header.Flags()&(FlagPublicReset|FlagConnectionID) != FlagPublicReset|FlagConnectionID {
+		return nil, ErrInvalidFlags
+	}
+
+	pr := PublicReset(b)
+	m, err := pr.message()
+	if err == handshake.ErrTruncated {
+		return nil, ErrTruncated
+	}
+	if err != nil || m.Tag != handshake.TagPRST {
+		return nil, ErrInvalidMessage
+	}
+	if value, ok := m.Get(handshake.TagRNON); !ok || len(value) != 8 {
+		return nil, ErrInvalidMessage
+	}
+	if value, ok := m.Get(handshake.TagRSEQ); !ok || len(value) != 8 {
+		return nil, ErrInvalidMessage
+	}
+	if value, ok := m.Get(handshake.TagCADR); ok && decodeAddress(value) == nil {
+		return nil, ErrInvalidMessage
+	}
+	return pr, nil
+}
+
 // SetConnectionID sets the connection id.
 func (pr PublicReset) SetConnectionID(value uint64) {
 	header := Header(pr)
@@ -15,7 +70,124 @@ func (pr PublicReset) ConnectionID() uint64 {
 	return Header(pr).ConnectionID()
 }
 
+// SetMessage sets the message with the provided nonce proof, rejected packet number and client
+// address. If the client address is nil, it's omitted. The buffer has to hold PublicResetLen bytes.
+// Since the message follows the header, SetMessage has to be called after SetConnectionID.
+func (pr PublicReset) SetMessage(nonceProof, rejectedPacketNumber uint64, clientAddress *net.UDPAddr) {
+	b, err := newPublicResetMessage(nonceProof, rejectedPacketNumber, clientAddress).Marshal()
+	if err != nil {
+		panic(err)
+	}
+	offset := Header(pr).Len()
+	pr.ensureLen(offset + len(b))
+	copy(pr[offset:], b)
+}
+
+// NonceProof returns the nonce proof. If the message has no valid nonce proof, zero is returned.
+func (pr PublicReset) NonceProof() uint64 {
+	return pr.uint64Value(handshake.TagRNON)
+}
+
+// RejectedPacketNumber returns the number of the packet that caused the public reset. If the
+// message has no valid rejected packet number, zero is returned.
+func (pr PublicReset) RejectedPacketNumber() uint64 {
+	return pr.uint64Value(handshake.TagRSEQ)
+}
+
+// ClientAddress returns the client address as observed by the server. If the message has no valid
+// client address, nil is returned.
+func (pr PublicReset) ClientAddress() *net.UDPAddr {
+	m, err := pr.message()
+	if err != nil {
+		return nil
+	}
+	value, _ := m.Get(handshake.TagCADR)
+	return decodeAddress(value)
+}
+
 // Len returns the length of the packet including the header.
 func (pr PublicReset) Len() int {
 	return len(pr)
 }
+
+func (pr PublicReset) message() (*handshake.Message, error) {
+	m := &handshake.Message{}
+	if err := m.Unmarshal(pr[Header(pr).Len():]); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (pr PublicReset) uint64Value(tag handshake.Tag) uint64 {
+	m, err := pr.message()
+	if err != nil {
+		return 0
+	}
+	value, _ := m.Get(tag)
+	if len(value) != 8 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(value)
+}
+
+func (pr PublicReset) ensureLen(l int) {
+	if len(pr) < l {
+		panic(fmt.Sprintf("expected buffer to have at least %d bytes, got %d", l, len(pr)))
+	}
+}
+
+func newPublicResetMessage(nonceProof, rejectedPacketNumber uint64, clientAddress *net.UDPAddr) *handshake.Message {
+	m := handshake.NewMessage(handshake.TagPRST)
+
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, nonceProof)
+	m.Set(handshake.TagRNON, value)
+
+	value = make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, rejectedPacketNumber)
+	m.Set(handshake.TagRSEQ, value)
+
+	if clientAddress != nil {
+		m.Set(handshake.TagCADR, encodeAddress(clientAddress))
+	}
+	return m
+}
+
+// encodeAddress returns the address family, the ip and the port of the provided address.
+func encodeAddress(addr *net.UDPAddr) []byte {
+	family, ip := uint16(addressFamilyIPv6), addr.IP.To16()
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		family, ip = addressFamilyIPv4, ip4
+	}
+
+	b := make([]byte, 2+len(ip)+2)
+	binary.LittleEndian.PutUint16(b, family)
+	copy(b[2:], ip)
+	binary.LittleEndian.PutUint16(b[2+len(ip):], uint16(addr.Port))
+	return b
+}
+
+// decodeAddress returns the address encoded in the provided buffer. If the buffer doesn't hold a
+// valid address, nil is returned.
+func decodeAddress(b []byte) *net.UDPAddr {
+	if len(b) < 2 {
+		return nil
+	}
+	ipLen := 0
+	switch binary.LittleEndian.Uint16(b) {
+	case addressFamilyIPv4:
+		ipLen = net.IPv4len
+	case addressFamilyIPv6:
+		ipLen = net.IPv6len
+	default:
+		return nil
+	}
+	if len(b) != 2+ipLen+2 {
+		return nil
+	}
+
+	return &net.UDPAddr{
+		IP:   net.IP(append([]byte(nil), b[2:2+ipLen]...)),
+		Port: int(binary.LittleEndian.Uint16(b[2+ipLen:])),
+	}
+}
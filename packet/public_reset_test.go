@@ -1,29 +1,60 @@
 package packet_test
 
 import (
+	"net"
 	"testing"
 
 	"github.com/simia-tech/go-quic/packet"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPublicReset(t *testing.T) {
 	testCases := []struct {
-		name         string
-		connectionID uint64
-		bytes        []byte
+		name                 string
+		connectionID         uint64
+		nonceProof           uint64
+		rejectedPacketNumber uint64
+		clientAddress        *net.UDPAddr
+		bytes                []byte
 	}{
-		{"Regular", 1,
-			[]byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"WithoutAddress", 1, 0x0807060504030201, 0x0a09, nil,
+			[]byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				'P', 'R', 'S', 'T', 0x02, 0x00, 0x00, 0x00,
+				'R', 'N', 'O', 'N', 0x08, 0x00, 0x00, 0x00,
+				'R', 'S', 'E', 'Q', 0x10, 0x00, 0x00, 0x00,
+				0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+				0x09, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"IPv4Address", 1, 0x0807060504030201, 0x0a09, &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4).To4(), Port: 0x1234},
+			[]byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				'P', 'R', 'S', 'T', 0x03, 0x00, 0x00, 0x00,
+				'R', 'N', 'O', 'N', 0x08, 0x00, 0x00, 0x00,
+				'R', 'S', 'E', 'Q', 0x10, 0x00, 0x00, 0x00,
+				'C', 'A', 'D', 'R', 0x18, 0x00, 0x00, 0x00,
+				0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+				0x09, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x02, 0x00, 0x01, 0x02, 0x03, 0x04, 0x34, 0x12}},
+		{"IPv6Address", 1, 0x0807060504030201, 0x0a09, &net.UDPAddr{IP: net.IPv6loopback, Port: 0x1234},
+			[]byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				'P', 'R', 'S', 'T', 0x03, 0x00, 0x00, 0x00,
+				'R', 'N', 'O', 'N', 0x08, 0x00, 0x00, 0x00,
+				'R', 'S', 'E', 'Q', 0x10, 0x00, 0x00, 0x00,
+				'C', 'A', 'D', 'R', 0x24, 0x00, 0x00, 0x00,
+				0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+				0x09, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x34, 0x12}},
 	}
 
 	t.Run("Write", func(t *testing.T) {
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
+				require.Equal(t, len(testCase.bytes), packet.PublicResetLen(testCase.clientAddress))
 				buffer := make([]byte, len(testCase.bytes))
 
 				prp := packet.PublicReset(buffer)
 				prp.SetConnectionID(testCase.connectionID)
+				prp.SetMessage(testCase.nonceProof, testCase.rejectedPacketNumber, testCase.clientAddress)
 
 				assert.Equal(t, len(testCase.bytes), prp.Len())
 				assert.Equal(t, testCase.bytes, buffer)
@@ -36,7 +67,61 @@ func TestPublicReset(t *testing.T) {
 			t.Run(testCase.name, func(t *testing.T) {
 				prp := packet.PublicReset(testCase.bytes)
 				assert.Equal(t, testCase.connectionID, prp.ConnectionID())
+				assert.Equal(t, testCase.nonceProof, prp.NonceProof())
+				assert.Equal(t, testCase.rejectedPacketNumber, prp.RejectedPacketNumber())
+				assert.Equal(t, testCase.clientAddress, prp.ClientAddress())
 			})
 		}
 	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				prp, err := packet.ParsePublicReset(testCase.bytes)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.connectionID, prp.ConnectionID())
+
+				_, err = packet.ParsePublicReset(testCase.bytes[:len(testCase.bytes)-1])
+				assert.Equal(t, packet.ErrTruncated, err)
+			})
+		}
+	})
+}
+
+func TestParsePublicResetErrors(t *testing.T) {
+	header := []byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	testCases := []struct {
+		name    string
+		message []byte
+		err     error
+	}{
+		{"NoMessage", []byte{}, packet.ErrTruncated},
+		{"InvalidTag", []byte{'C', 'H', 'L', 'O', 0x00, 0x00, 0x00, 0x00}, packet.ErrInvalidMessage},
+		{"MissingNonceProof", []byte{'P', 'R', 'S', 'T', 0x01, 0x00, 0x00, 0x00,
+			'R', 'S', 'E', 'Q', 0x08, 0x00, 0x00, 0x00,
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, packet.ErrInvalidMessage},
+		{"ShortRejectedPacketNumber", []byte{'P', 'R', 'S', 'T', 0x02, 0x00, 0x00, 0x00,
+			'R', 'N', 'O', 'N', 0x08, 0x00, 0x00, 0x00,
+			'R', 'S', 'E', 'Q', 0x09, 0x00, 0x00, 0x00,
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}, packet.ErrInvalidMessage},
+		{"InvalidAddressFamily", []byte{'P', 'R', 'S', 'T', 0x03, 0x00, 0x00, 0x00,
+			'R', 'N', 'O', 'N', 0x08, 0x00, 0x00, 0x00,
+			'R', 'S', 'E', 'Q', 0x10, 0x00, 0x00, 0x00,
+			'C', 'A', 'D', 'R', 0x18, 0x00, 0x00, 0x00,
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+			0x09, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x03, 0x00, 0x01, 0x02, 0x03, 0x04, 0x34, 0x12}, packet.ErrInvalidMessage},
+		{"InvalidTagOrder", []byte{'P', 'R', 'S', 'T', 0x02, 0x00, 0x00, 0x00,
+			'R', 'S', 'E', 'Q', 0x08, 0x00, 0x00, 0x00,
+			'R', 'N', 'O', 'N', 0x10, 0x00, 0x00, 0x00,
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+			0x09, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, packet.ErrInvalidMessage},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := packet.ParsePublicReset(append(append([]byte{}, header...), testCase.message...))
+			assert.Equal(t, testCase.err, err)
+		})
+	}
 }
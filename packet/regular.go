@@ -8,6 +8,25 @@ import (
 // Regular defines the regular packet type.
 type Regular []byte
 
+// ParseRegular validates the regular packet in the provided buffer. If the buffer is too short to
+// hold the header fields indicated by the flags, ErrTruncated is returned. If the flags don't
+// describe a regular packet, ErrInvalidFlags is returned. The accessors of the returned packet
+// don't panic.
+func ParseRegular(b []byte) (Regular, error) {
+	header, err := ParseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if header.Flags()&FlagPublicReset != 0x00 {
+		return nil, ErrInvalidFlags
+	}
+	r := Regular(b)
+	if len(r) < header.Len()+r.versionLen()+r.nonceLen()+r.packetNumberLen() {
+		return nil, ErrTruncated
+	}
+	return r, nil
+}
+
 // AddConnectionID adds the connection id.
 func (r Regular) AddConnectionID(value uint64) {
 	Header(r).AddConnectionID(value)
@@ -19,85 +38,106 @@ func (r Regular) ConnectionID() uint64 {
 }
 
 // AddVersion set the quic versions and the corresponding flag.
-func (r Regular) AddVersion(version uint32) {
+func (r Regular) AddVersion(version Version) {
 	header := Header(r)
 	offset := header.Len()
 	r.ensureLen(offset + 4)
 	header.SetFlags(FlagVersion)
-	binary.LittleEndian.PutUint32(r[offset:], version)
+	binary.BigEndian.PutUint32(r[offset:], uint32(version))
 }
 
-// Version returns the version.
-func (r Regular) Version() uint32 {
+// Version returns the version. If the packet has no version, zero is returned.
+func (r Regular) Version() Version {
+	if r.versionLen() == 0 {
+		return 0
+	}
 	header := Header(r)
 	offset := header.Len()
 	r.ensureLen(offset + 4)
-	return binary.LittleEndian.Uint32(r[offset:])
+	return Version(binary.BigEndian.Uint32(r[offset:]))
 }
 
-// AddPacketNumber sets the packet number and the corresponding header flags. The value has to be
-// uint8, uint16, uint32 or uint64. Values of other types will cause a panic.
-func (r Regular) AddPacketNumber(value interface{}) {
+// AddNonce adds the diversification nonce and sets the corresponding flag. The nonce has to be
+// NonceLen bytes long, otherwise a panic is caused.
+func (r Regular) AddNonce(nonce []byte) {
+	if len(nonce) != NonceLen {
+		panic(fmt.Sprintf("expected nonce to have %d bytes, got %d", NonceLen, len(nonce)))
+	}
 	header := Header(r)
 	offset := header.Len() + r.versionLen()
-	switch v := value.(type) {
-	case uint64:
-		r.ensureLen(offset + 6)
+	r.ensureLen(offset + NonceLen)
+	header.SetFlags(FlagNonce)
+	copy(r[offset:], nonce)
+}
+
+// Nonce returns the diversification nonce. If the packet has no nonce, nil is returned.
+func (r Regular) Nonce() []byte {
+	if r.nonceLen() == 0 {
+		return nil
+	}
+	offset := Header(r).Len() + r.versionLen()
+	r.ensureLen(offset + NonceLen)
+	return r[offset : offset+NonceLen]
+}
+
+// AddPacketNumber adds the packet number with the provided length in bytes and sets the
+// corresponding header flags. The length has to be 1, 2, 4 or 6. Other lengths will cause a panic.
+func (r Regular) AddPacketNumber(value uint64, length int) {
+	header := Header(r)
+	offset := header.Len() + r.versionLen() + r.nonceLen()
+	r.ensureLen(offset + length)
+	switch length {
+	case 6:
 		header.SetFlags(PacketNumberLen6)
-		binary.LittleEndian.PutUint32(r[offset:], uint32(v&0xffffffff))
-		binary.LittleEndian.PutUint16(r[offset+4:], uint16((v&0xffff00000000)>>32))
-	case uint32:
-		r.ensureLen(offset + 4)
+		binary.LittleEndian.PutUint32(r[offset:], uint32(value))
+		binary.LittleEndian.PutUint16(r[offset+4:], uint16(value>>32))
+	case 4:
 		header.SetFlags(PacketNumberLen4)
-		binary.LittleEndian.PutUint32(r[offset:], v)
-	case uint16:
-		r.ensureLen(offset + 2)
+		binary.LittleEndian.PutUint32(r[offset:], uint32(value))
+	case 2:
 		header.SetFlags(PacketNumberLen2)
-		binary.LittleEndian.PutUint16(r[offset:], v)
-	case uint8:
-		r.ensureLen(offset + 1)
+		binary.LittleEndian.PutUint16(r[offset:], uint16(value))
+	case 1:
 		header.SetFlags(PacketNumberLen1)
-		r[offset] = v
+		r[offset] = uint8(value)
 	default:
-		panic(fmt.Sprintf("cannot set packet number value of type %T", v))
+		panic(fmt.Sprintf("cannot set packet number with length %d", length))
 	}
 }
 
-// PacketNumber returns the connection id.
-func (r Regular) PacketNumber() interface{} {
+// PacketNumber returns the packet number as it's encoded in the packet. It has to be expanded with
+// ExpandPacketNumber to get the full packet number.
+func (r Regular) PacketNumber() uint64 {
 	header := Header(r)
-	offset := header.Len() + r.versionLen()
-	switch r[0] & PacketNumberMask {
-	case PacketNumberLen6:
-		r.ensureLen(offset + 6)
+	offset := header.Len() + r.versionLen() + r.nonceLen()
+	r.ensureLen(offset + r.packetNumberLen())
+	switch r.packetNumberLen() {
+	case 6:
 		return uint64(binary.LittleEndian.Uint32(r[offset:])) |
-			(uint64(binary.LittleEndian.Uint16(r[offset+4:])) << 32)
-	case PacketNumberLen4:
-		r.ensureLen(offset + 4)
-		return binary.LittleEndian.Uint32(r[offset:])
-	case PacketNumberLen2:
-		r.ensureLen(offset + 2)
-		return binary.LittleEndian.Uint16(r[offset:])
-	case PacketNumberLen1:
-		r.ensureLen(offset + 1)
-		return r[offset]
+			uint64(binary.LittleEndian.Uint16(r[offset+4:]))<<32
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(r[offset:]))
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(r[offset:]))
 	}
-	return nil
+	return uint64(r[offset])
+}
+
+// PacketNumberLen returns the length of the packet number in bytes.
+func (r Regular) PacketNumberLen() int {
+	return r.packetNumberLen()
 }
 
 // SetData sets the packet's payload data.
 func (r Regular) SetData(data []byte) {
-	header := Header(r)
-	offset := header.Len() + r.versionLen() + r.packetNumberLen()
+	offset := r.headerLen()
 	r.ensureLen(offset + len(data))
 	copy(r[offset:], data)
 }
 
 // Data returns the packet's payload data.
 func (r Regular) Data() []byte {
-	header := Header(r)
-	offset := header.Len() + r.versionLen() + r.packetNumberLen()
-	return r[offset:]
+	return r[r.headerLen():]
 }
 
 // Len returns the length of the packet including the header.
@@ -111,6 +151,10 @@ func (r Regular) ensureLen(l int) {
 	}
 }
 
+func (r Regular) headerLen() int {
+	return Header(r).Len() + r.versionLen() + r.nonceLen() + r.packetNumberLen()
+}
+
 func (r Regular) versionLen() int {
 	if Header(r).Flags()&FlagVersion == 0x00 {
 		return 0
@@ -118,6 +162,13 @@ func (r Regular) versionLen() int {
 	return 4
 }
 
+func (r Regular) nonceLen() int {
+	if Header(r).Flags()&FlagNonce == 0x00 {
+		return 0
+	}
+	return NonceLen
+}
+
 func (r Regular) packetNumberLen() int {
 	switch Header(r).Flags() & PacketNumberMask {
 	case PacketNumberLen6:
@@ -3,31 +3,43 @@ package packet_test
 import (
 	"testing"
 
+	"github.com/simia-tech/go-quic/frame"
 	"github.com/simia-tech/go-quic/packet"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegular(t *testing.T) {
+	nonce := make([]byte, packet.NonceLen)
+	for index := range nonce {
+		nonce[index] = byte(0xa0 + index)
+	}
+
 	testCases := []struct {
 		name string
 
-		connectionID interface{}
-		version      interface{}
-		nonce        []byte
-		packetNumber interface{}
-		data         []byte
+		connectionID    interface{}
+		version         interface{}
+		nonce           []byte
+		packetNumber    uint64
+		packetNumberLen int
+		data            []byte
 
 		bytes []byte
 	}{
-		{"Version", uint64(1), uint32(2), nil, uint64(3), []byte{0x04, 0x05, 0x06},
-			[]byte{0x39, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x05, 0x06}},
-		{"PacketNumber6", uint64(1), nil, nil, uint64(2), []byte{0x04, 0x05, 0x06},
+		{"Version", uint64(1), packet.VersionQ039, nil, 3, 6, []byte{0x04, 0x05, 0x06},
+			[]byte{0x39, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x05, 0x06}},
+		{"Nonce", uint64(1), nil, nonce, 3, 2, []byte{0x04, 0x05, 0x06},
+			append(append([]byte{0x1c, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, nonce...), 0x03, 0x00, 0x04, 0x05, 0x06)},
+		{"VersionNonce", uint64(1), packet.VersionQ039, nonce, 3, 1, []byte{0x04, 0x05, 0x06},
+			append(append([]byte{0x0d, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9'}, nonce...), 0x03, 0x04, 0x05, 0x06)},
+		{"PacketNumber6", uint64(1), nil, nil, 2, 6, []byte{0x04, 0x05, 0x06},
 			[]byte{0x38, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x05, 0x06}},
-		{"PacketNumber4", uint64(1), nil, nil, uint32(2), []byte{0x04, 0x05, 0x06},
+		{"PacketNumber4", uint64(1), nil, nil, 2, 4, []byte{0x04, 0x05, 0x06},
 			[]byte{0x28, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x04, 0x05, 0x06}},
-		{"PacketNumber2", uint64(1), nil, nil, uint16(2), []byte{0x04, 0x05, 0x06},
+		{"PacketNumber2", uint64(1), nil, nil, 2, 2, []byte{0x04, 0x05, 0x06},
 			[]byte{0x18, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x04, 0x05, 0x06}},
-		{"PacketNumber1", uint64(1), nil, nil, uint8(2), []byte{0x04, 0x05, 0x06},
+		{"PacketNumber1", uint64(1), nil, nil, 2, 1, []byte{0x04, 0x05, 0x06},
 			[]byte{0x08, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x04, 0x05, 0x06}},
 	}
 
@@ -41,14 +53,12 @@ func TestRegular(t *testing.T) {
 					regular.AddConnectionID(testCase.connectionID.(uint64))
 				}
 				if testCase.version != nil {
-					regular.AddVersion(testCase.version.(uint32))
+					regular.AddVersion(testCase.version.(packet.Version))
 				}
 				if testCase.nonce != nil {
-					// regular.AddNonce(testCase.nonce)
-				}
-				if testCase.packetNumber != nil {
-					regular.AddPacketNumber(testCase.packetNumber)
+					regular.AddNonce(testCase.nonce)
 				}
+				regular.AddPacketNumber(testCase.packetNumber, testCase.packetNumberLen)
 				regular.SetData(testCase.data)
 
 				assert.Equal(t, len(testCase.bytes), regular.Len())
@@ -66,13 +76,103 @@ func TestRegular(t *testing.T) {
 					assert.Equal(t, testCase.version, regular.Version())
 				}
 				if testCase.nonce != nil {
-					// assert.Equal(t, testCase.nonce, regular.Nonce())
-				}
-				if testCase.packetNumber != nil {
-					assert.Equal(t, testCase.packetNumber, regular.PacketNumber())
+					assert.Equal(t, testCase.nonce, regular.Nonce())
 				}
+				assert.Equal(t, testCase.packetNumber, regular.PacketNumber())
+				assert.Equal(t, testCase.packetNumberLen, regular.PacketNumberLen())
+				assert.Equal(t, testCase.data, regular.Data())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				regular, err := packet.ParseRegular(testCase.bytes)
+				require.NoError(t, err)
 				assert.Equal(t, testCase.data, regular.Data())
+
+				for l := 0; l < len(testCase.bytes)-len(testCase.data); l++ {
+					_, err := packet.ParseRegular(testCase.bytes[:l])
+					assert.Equal(t, packet.ErrTruncated, err, "length %d", l)
+				}
 			})
 		}
 	})
 }
+
+func TestParseRegularErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		bytes []byte
+		err   error
+	}{
+		{"Empty", []byte{}, packet.ErrTruncated},
+		{"ReservedFlags", []byte{0x48, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}, packet.ErrInvalidFlags},
+		{"PublicReset", []byte{0x0a, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}, packet.ErrInvalidFlags},
+		{"TruncatedConnectionID", []byte{0x08, 0x01, 0x00}, packet.ErrTruncated},
+		{"TruncatedNonce", []byte{0x0c, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02}, packet.ErrTruncated},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := packet.ParseRegular(testCase.bytes)
+			assert.Equal(t, testCase.err, err)
+		})
+	}
+}
+
+func TestRegularWithStreamAllocations(t *testing.T) {
+	packetBuffer := make([]byte, 1+8+2+1+4+8+2+3)
+	frameBuffer := make([]byte, 1+4+8+2+3)
+	data := []byte{0x01, 0x02, 0x03}
+
+	allocations := testing.AllocsPerRun(100, func() {
+		buildAndParseRegularWithStream(packetBuffer, frameBuffer, data)
+	})
+	assert.Equal(t, 0.0, allocations)
+}
+
+func BenchmarkRegularWithStream(b *testing.B) {
+	packetBuffer := make([]byte, 1+8+2+1+4+8+2+3)
+	frameBuffer := make([]byte, 1+4+8+2+3)
+	data := []byte{0x01, 0x02, 0x03}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		buildAndParseRegularWithStream(packetBuffer, frameBuffer, data)
+	}
+}
+
+func buildAndParseRegularWithStream(packetBuffer, frameBuffer, data []byte) {
+	for index := range packetBuffer {
+		packetBuffer[index] = 0
+	}
+	for index := range frameBuffer {
+		frameBuffer[index] = 0
+	}
+
+	stream := frame.Stream(frameBuffer)
+	stream.SetStreamID(3, 4)
+	stream.AddOffset(1024, 8)
+	stream.SetData(data)
+
+	regular := packet.Regular(packetBuffer)
+	regular.AddConnectionID(1)
+	regular.AddPacketNumber(2, 2)
+	regular.SetData(frameBuffer)
+
+	parsedRegular, err := packet.ParseRegular(packetBuffer)
+	if err != nil {
+		panic(err)
+	}
+	parsedStream, err := frame.ParseStream(parsedRegular.Data())
+	if err != nil {
+		panic(err)
+	}
+	if parsedRegular.PacketNumber() != 2 || parsedStream.StreamID() != 3 || parsedStream.Offset() != 1024 ||
+		len(parsedStream.Data()) != len(data) {
+		panic("unexpected packet content")
+	}
+}
@@ -0,0 +1,47 @@
+package packet
+
+// Sealer seals the payload of packets. The packet header is passed as associated data, so it's
+// authenticated, but not encrypted.
+type Sealer interface {
+	// Seal seals the plaintext of the packet with the provided number and appends the result to
+	// dst. The associated data is authenticated together with the plaintext.
+	Seal(dst []byte, packetNumber uint64, plaintext, associatedData []byte) []byte
+
+	// Overhead returns the number of bytes a sealed payload is longer than the plaintext.
+	Overhead() int
+}
+
+// Opener opens the payload of packets sealed by the peer's Sealer.
+type Opener interface {
+	// Open authenticates the ciphertext of the packet with the provided number together with the
+	// associated data and appends the plaintext to dst. If the ciphertext is shorter than the
+	// overhead, ErrTruncated is returned. If the authentication fails, ErrAuthentication is
+	// returned.
+	Open(dst []byte, packetNumber uint64, ciphertext, associatedData []byte) ([]byte, error)
+}
+
+// Seal returns a copy of the packet with the payload sealed by the provided sealer. The header is
+// used as associated data. The sealed packet is Overhead bytes longer, which has to be considered
+// when the packet is built.
+func (r Regular) Seal(s Sealer, packetNumber uint64) Regular {
+	header := r[:r.headerLen()]
+
+	sealed := make([]byte, len(header), len(r)+s.Overhead())
+	copy(sealed, header)
+	return Regular(s.Seal(sealed, packetNumber, r.Data(), header))
+}
+
+// Open returns a copy of the packet with the payload opened by the provided opener. The header is
+// used as associated data. If the payload is too short or can't be authenticated, the opener's
+// error is returned.
+func (r Regular) Open(o Opener, packetNumber uint64) (Regular, error) {
+	header := r[:r.headerLen()]
+
+	opened := make([]byte, len(header), len(r))
+	copy(opened, header)
+	opened, err := o.Open(opened, packetNumber, r.Data(), header)
+	if err != nil {
+		return nil, err
+	}
+	return Regular(opened), nil
+}
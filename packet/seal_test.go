@@ -0,0 +1,38 @@
+package packet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestRegularSeal(t *testing.T) {
+	header := []byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 0x02}
+	payload := []byte{0x07}
+	ne := packet.NewNullEncryption(packet.PerspectiveClient, packet.VersionQ039)
+
+	regular := packet.Regular(make([]byte, len(header)+len(payload)))
+	regular.AddConnectionID(1)
+	regular.AddVersion(packet.VersionQ039)
+	regular.AddPacketNumber(2, 1)
+	regular.SetData(payload)
+
+	sealed := regular.Seal(ne, 2)
+	assert.Equal(t, regular.Len()+ne.Overhead(), sealed.Len())
+	assert.Equal(t, header, []byte(sealed[:len(header)]))
+	assert.Equal(t, ne.Seal(nil, 2, payload, header), sealed.Data())
+
+	parsed, err := packet.ParseRegular(sealed)
+	require.NoError(t, err)
+	opened, err := parsed.Open(ne, 2)
+	require.NoError(t, err)
+	assert.Equal(t, regular, opened)
+
+	// The header is authenticated.
+	sealed[1] = 0x02
+	_, err = sealed.Open(ne, 2)
+	assert.Equal(t, packet.ErrAuthentication, err)
+}
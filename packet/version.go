@@ -0,0 +1,51 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Version defines a quic version. Its value is the version number as used by IETF quic, which is
+// the version's four byte tag in network byte order, e.g. 0x51303339 for "Q039". Since the first
+// character is the most significant byte, gquic versions compare like their numbers.
+type Version uint32
+
+// Definition of gquic versions.
+const (
+	VersionQ035 Version = 'Q'<<24 | '0'<<16 | '3'<<8 | '5'
+	VersionQ036 Version = 'Q'<<24 | '0'<<16 | '3'<<8 | '6'
+	VersionQ037 Version = 'Q'<<24 | '0'<<16 | '3'<<8 | '7'
+	VersionQ038 Version = 'Q'<<24 | '0'<<16 | '3'<<8 | '8'
+	VersionQ039 Version = 'Q'<<24 | '0'<<16 | '3'<<8 | '9'
+)
+
+// ParseVersion returns the version with the provided tag, e.g. "Q039". If the tag doesn't consist
+// of four printable characters, ErrInvalidVersion is returned.
+func ParseVersion(tag string) (Version, error) {
+	if len(tag) != 4 {
+		return 0, ErrInvalidVersion
+	}
+	for index := 0; index < len(tag); index++ {
+		if !isPrintable(tag[index]) {
+			return 0, ErrInvalidVersion
+		}
+	}
+	return Version(binary.BigEndian.Uint32([]byte(tag))), nil
+}
+
+// String returns the version's tag, e.g. "Q039". If the tag isn't printable, like the ones of IETF
+// versions, the version number is returned in hex.
+func (v Version) String() string {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	for _, c := range b {
+		if !isPrintable(c) {
+			return fmt.Sprintf("0x%08x", uint32(v))
+		}
+	}
+	return string(b)
+}
+
+func isPrintable(c byte) bool {
+	return c >= 0x20 && c <= 0x7e
+}
@@ -8,6 +8,23 @@ import (
 // VersionNegotiation defines the version negotiation packet type.
 type VersionNegotiation []byte
 
+// ParseVersionNegotiation validates the version negotiation packet in the provided buffer. If the
+// buffer doesn't end on a version boundary, ErrTruncated is returned. If the flags don't describe
+// a version negotiation packet, ErrInvalidFlags is returned.
+func ParseVersionNegotiation(b []byte) (VersionNegotiation, error) {
+	header, err := ParseHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	if header.Flags()&(FlagVersion|FlagPublicReset) != FlagVersion {
+		return nil, ErrInvalidFlags
+	}
+	if (len(b)-header.Len())%4 != 0 {
+		return nil, ErrTruncated
+	}
+	return VersionNegotiation(b), nil
+}
+
 // SetConnectionID sets the connection id.
 func (vn VersionNegotiation) SetConnectionID(value uint64) {
 	header := Header(vn)
@@ -21,25 +38,25 @@ func (vn VersionNegotiation) ConnectionID() uint64 {
 }
 
 // SetVersions sets the versions.
-func (vn VersionNegotiation) SetVersions(values []uint32) {
+func (vn VersionNegotiation) SetVersions(values []Version) {
 	header := Header(vn)
 	offset := header.Len()
 	vn.ensureLen(offset + (len(values) * 4))
 
 	for _, value := range values {
-		binary.LittleEndian.PutUint32(vn[offset:], value)
+		binary.BigEndian.PutUint32(vn[offset:], uint32(value))
 		offset += 4
 	}
 }
 
 // Versions returns the versions.
-func (vn VersionNegotiation) Versions() []uint32 {
+func (vn VersionNegotiation) Versions() []Version {
 	header := Header(vn)
 	offset := header.Len()
 	count := (len(vn) - offset) / 4
-	versions := make([]uint32, count)
+	versions := make([]Version, count)
 	for index := 0; index < count; index++ {
-		versions[index] = binary.LittleEndian.Uint32(vn[offset:])
+		versions[index] = Version(binary.BigEndian.Uint32(vn[offset:]))
 		offset += 4
 	}
 	return versions
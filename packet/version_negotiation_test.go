@@ -5,19 +5,20 @@ import (
 
 	"github.com/simia-tech/go-quic/packet"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVersionNegotitation(t *testing.T) {
 	testCases := []struct {
 		name         string
 		connectionID uint64
-		versions     []uint32
+		versions     []packet.Version
 		bytes        []byte
 	}{
-		{"One", 1, []uint32{1},
-			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}},
-		{"Two", 1, []uint32{1, 2},
-			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}},
+		{"One", 1, []packet.Version{packet.VersionQ039},
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9'}},
+		{"Two", 1, []packet.Version{packet.VersionQ039, packet.VersionQ035},
+			[]byte{0x09, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'Q', '0', '3', '9', 'Q', '0', '3', '5'}},
 	}
 
 	t.Run("Write", func(t *testing.T) {
@@ -44,4 +45,17 @@ func TestVersionNegotitation(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				vnp, err := packet.ParseVersionNegotiation(testCase.bytes)
+				require.NoError(t, err)
+				assert.Equal(t, testCase.versions, vnp.Versions())
+
+				_, err = packet.ParseVersionNegotiation(testCase.bytes[:len(testCase.bytes)-1])
+				assert.Equal(t, packet.ErrTruncated, err)
+			})
+		}
+	})
 }
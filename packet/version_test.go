@@ -0,0 +1,61 @@
+package packet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestVersion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version packet.Version
+		text    string
+	}{
+		{"Q035", packet.VersionQ035, "Q035"},
+		{"Q039", packet.VersionQ039, "Q039"},
+		{"Number", packet.Version(0x51303339), "Q039"},
+		{"IETF", packet.Version(0xff00001d), "0xff00001d"},
+	}
+
+	t.Run("String", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				assert.Equal(t, testCase.text, testCase.version.String())
+			})
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				version, err := packet.ParseVersion(testCase.text)
+				if len(testCase.text) != 4 {
+					assert.Equal(t, packet.ErrInvalidVersion, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, testCase.version, version)
+			})
+		}
+	})
+}
+
+func TestParseVersionErrors(t *testing.T) {
+	for _, tag := range []string{"", "Q03", "Q0390", "Q03\xff"} {
+		_, err := packet.ParseVersion(tag)
+		assert.Equal(t, packet.ErrInvalidVersion, err, "tag %q", tag)
+	}
+}
+
+func TestVersionOrdering(t *testing.T) {
+	assert.True(t, packet.VersionQ035 < packet.VersionQ036)
+	assert.True(t, packet.VersionQ038 < packet.VersionQ039)
+
+	q100, err := packet.ParseVersion("Q100")
+	require.NoError(t, err)
+	assert.True(t, packet.VersionQ039 < q100)
+}
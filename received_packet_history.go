@@ -0,0 +1,82 @@
+package quic
+
+import "github.com/simia-tech/go-quic/frame"
+
+// maxAckRanges defines the maximal number of ranges of received packet numbers that are tracked.
+// If more gaps occur, the lowest ranges are dropped.
+const maxAckRanges = 32
+
+// receivedPacketHistory tracks the received packet numbers as ranges in descending order, so they
+// can be acknowledged. It's not safe for concurrent use.
+type receivedPacketHistory struct {
+	ranges       []frame.AckRange
+	leastUnacked uint64
+}
+
+// receivedPacket records the provided packet number. If the packet has been received before or is
+// below the least unacked packet number, false is returned.
+func (h *receivedPacketHistory) receivedPacket(packetNumber uint64) bool {
+	if packetNumber < h.leastUnacked {
+		return false
+	}
+
+	for index := range h.ranges {
+		r := &h.ranges[index]
+		switch {
+		case packetNumber >= r.Smallest && packetNumber <= r.Largest:
+			return false
+		case packetNumber == r.Largest+1:
+			r.Largest = packetNumber
+			return true
+		case packetNumber > r.Largest+1:
+			h.insertRange(index, packetNumber)
+			return true
+		case packetNumber+1 == r.Smallest:
+			r.Smallest = packetNumber
+			if next := index + 1; next < len(h.ranges) && h.ranges[next].Largest+1 == packetNumber {
+				r.Smallest = h.ranges[next].Smallest
+				h.ranges = append(h.ranges[:next], h.ranges[next+1:]...)
+			}
+			return true
+		}
+	}
+	h.insertRange(len(h.ranges), packetNumber)
+	return true
+}
+
+// ignoreBelow drops all packet numbers below the provided one. Packets below it won't be
+// acknowledged anymore.
+func (h *receivedPacketHistory) ignoreBelow(packetNumber uint64) {
+	if packetNumber <= h.leastUnacked {
+		return
+	}
+	h.leastUnacked = packetNumber
+
+	for index := range h.ranges {
+		r := &h.ranges[index]
+		if r.Largest < packetNumber {
+			h.ranges = h.ranges[:index]
+			return
+		}
+		if r.Smallest < packetNumber {
+			r.Smallest = packetNumber
+			h.ranges = h.ranges[:index+1]
+			return
+		}
+	}
+}
+
+// ackRanges returns the ranges of received packet numbers in descending order.
+func (h *receivedPacketHistory) ackRanges() []frame.AckRange {
+	return h.ranges
+}
+
+func (h *receivedPacketHistory) insertRange(index int, packetNumber uint64) {
+	h.ranges = append(h.ranges, frame.AckRange{})
+	copy(h.ranges[index+1:], h.ranges[index:])
+	h.ranges[index] = frame.AckRange{Smallest: packetNumber, Largest: packetNumber}
+
+	if len(h.ranges) > maxAckRanges {
+		h.ranges = h.ranges[:maxAckRanges]
+	}
+}
@@ -0,0 +1,120 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/simia-tech/go-quic/frame"
+)
+
+// lossThreshold defines the number of packet numbers by which the largest acknowledged packet has
+// to exceed an unacknowledged packet, before the packet is considered lost.
+const lossThreshold = 3
+
+// sentPacket defines a sent packet with a retransmittable payload.
+type sentPacket struct {
+	packetNumber uint64
+	payload      []byte
+	sentTime     time.Time
+}
+
+// sentPacketHistory tracks the sent packets with retransmittable payloads in ascending order of
+// their packet numbers, until they have been acknowledged or declared lost. It's not safe for
+// concurrent use.
+type sentPacketHistory struct {
+	packets []sentPacket
+}
+
+// isRetransmittable returns true, if the payload starts with a frame that has to be sent again, if
+// it gets lost.
+func isRetransmittable(payload []byte) bool {
+	frameType, err := frame.ParseType(payload)
+	if err != nil {
+		return false
+	}
+	switch frameType.Type() {
+	case frame.TypeStream, frame.TypeResetStream, frame.TypeGoAway, frame.TypeWindowUpdate:
+		return true
+	}
+	return false
+}
+
+// sentPacket records the packet with the provided packet number. The payload is copied.
+func (h *sentPacketHistory) sentPacket(packetNumber uint64, payload []byte, sentTime time.Time) {
+	p := sentPacket{
+		packetNumber: packetNumber,
+		payload:      append([]byte(nil), payload...),
+		sentTime:     sentTime,
+	}
+
+	// Concurrently built packets might be recorded out of order.
+	index := len(h.packets)
+	for index > 0 && h.packets[index-1].packetNumber > packetNumber {
+		index--
+	}
+	h.packets = append(h.packets, sentPacket{})
+	copy(h.packets[index+1:], h.packets[index:])
+	h.packets[index] = p
+}
+
+// acknowledge removes the packets within the provided ack ranges. It returns the number of removed
+// packets and, if the largest acknowledged packet has been removed, the time it has been sent.
+func (h *sentPacketHistory) acknowledge(ranges []frame.AckRange) (int, time.Time) {
+	if len(ranges) == 0 {
+		return 0, time.Time{}
+	}
+	largestAcked := ranges[0].Largest
+
+	sentTime := time.Time{}
+	packets := h.packets[:0]
+	for _, p := range h.packets {
+		if !containsPacketNumber(ranges, p.packetNumber) {
+			packets = append(packets, p)
+			continue
+		}
+		if p.packetNumber == largestAcked {
+			sentTime = p.sentTime
+		}
+	}
+	acked := len(h.packets) - len(packets)
+	h.packets = packets
+	return acked, sentTime
+}
+
+// lostPackets removes and returns the packets that are considered lost. A packet is lost, if the
+// largest acknowledged packet number exceeds it by at least lossThreshold or if it has been sent
+// before the provided time.
+func (h *sentPacketHistory) lostPackets(largestAcked uint64, sentBefore time.Time) []sentPacket {
+	lost := []sentPacket{}
+	packets := h.packets[:0]
+	for _, p := range h.packets {
+		if p.packetNumber+lossThreshold <= largestAcked || p.sentTime.Before(sentBefore) {
+			lost = append(lost, p)
+			continue
+		}
+		packets = append(packets, p)
+	}
+	h.packets = packets
+	return lost
+}
+
+// oldestSentTime returns the time the oldest packet has been sent. If the history is empty, false
+// is returned.
+func (h *sentPacketHistory) oldestSentTime() (time.Time, bool) {
+	oldest := time.Time{}
+	for _, p := range h.packets {
+		if oldest.IsZero() || p.sentTime.Before(oldest) {
+			oldest = p.sentTime
+		}
+	}
+	return oldest, !oldest.IsZero()
+}
+
+// containsPacketNumber returns true, if the packet number is within one of the ranges.
+func containsPacketNumber(ranges []frame.AckRange, packetNumber uint64) bool {
+	for _, r := range ranges {
+		if packetNumber >= r.Smallest && packetNumber <= r.Largest {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1203 @@
+package quic
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/simia-tech/go-quic/frame"
+	"github.com/simia-tech/go-quic/handshake"
+	"github.com/simia-tech/go-quic/packet"
+)
+
+// MaxPacketSize defines the maximal size of a packet sent by a session.
+const MaxPacketSize = 1350
+
+// Stream ids are odd for streams opened by the client and even for streams opened by the server.
+// Stream 1 is reserved for the crypto handshake.
+const (
+	cryptoStreamID      = 1
+	firstClientStreamID = 3
+	firstServerStreamID = 2
+)
+
+// maxOpenStreams defines the maximal number of concurrently open streams per session.
+const maxOpenStreams = 100
+
+// maxReceivePacketSize defines the maximal size of a received packet.
+const maxReceivePacketSize = 1500
+
+// Received packets are acknowledged after every second retransmittable packet or, at the latest,
+// after the ack delay.
+const (
+	ackDelay           = 25 * time.Millisecond
+	ackPacketThreshold = 2
+)
+
+// Packets with retransmittable frames are sent again, if they haven't been acknowledged within the
+// retransmission timeout. It's twice the smoothed round trip time, but at least
+// minRetransmissionTimeout, and doubles with each consecutive timeout up to
+// maxRetransmissionTimeout.
+const (
+	initialRoundTripTime     = 100 * time.Millisecond
+	minRetransmissionTimeout = 200 * time.Millisecond
+	maxRetransmissionTimeout = 60 * time.Second
+)
+
+// defaultIdleTimeout defines the time after which a session without network activity is closed.
+const defaultIdleTimeout = 30 * time.Second
+
+// maxAckFrameLen defines the maximal length of a sent acknowledge frame.
+const maxAckFrameLen = 1 + 6 + 2 + 1 + 6 + maxAckRanges*(1+6) + 1
+
+// Errors returned by sessions.
+var (
+	ErrClosed             = errors.New("quic: session closed")
+	ErrTooManyOpenStreams = errors.New("quic: too many open streams")
+	ErrPublicReset        = errors.New("quic: session reset by peer")
+	ErrVersionNegotiation = errors.New("quic: peer does not support version")
+)
+
+// ErrGoAway is returned by OpenStream and OpenStreamSync if the session is going away. It's also
+// returned by streams that have been opened after the peer has started going away. It's a
+// temporary net.Error, since the stream can be opened on a new session.
+var ErrGoAway error = &goAwayError{}
+
+type goAwayError struct{}
+
+func (*goAwayError) Error() string   { return "quic: session is going away" }
+func (*goAwayError) Timeout() bool   { return false }
+func (*goAwayError) Temporary() bool { return true }
+
+// Session defines a quic connection that multiplexes many streams.
+type Session struct {
+	perspective  packet.Perspective
+	connectionID uint64
+	transport    transport
+
+	mu                  sync.Mutex
+	versions            []Version
+	version             Version
+	versionNegotiated   bool
+	unconfirmedPayloads [][]byte
+	packetNumber        uint64
+	largestAcked        uint64
+	largestReceived     uint64
+	largestReceivedTime time.Time
+	lastReceivedTime    time.Time
+	receivedPacket      bool
+	receivedPackets     receivedPacketHistory
+	sentPackets         sentPacketHistory
+	roundTripTime       time.Duration
+	retransmissions     int
+	retransmitTimer     *time.Timer
+	ackPending          int
+	ackTimer            *time.Timer
+	idleTimeout         time.Duration
+	idleTimer           *time.Timer
+	keepAlive           bool
+	lastPingTime        time.Time
+	streams             map[uint32]*Stream
+	closingStreams      map[uint32]*Stream
+	nextStreamID        uint32
+	highestPeerStreamID uint32
+	closedPeerStreams   closedStreamHistory
+	goAwaySent          bool
+	goAwayReceived      bool
+	flow                flowController
+	acceptQueue         []*Stream
+	acceptSignal        chan struct{}
+	openSignal          chan struct{}
+	err                 error
+	closed              chan struct{}
+}
+
+func newSession(p packet.Perspective, connectionID uint64, t transport) *Session {
+	s := &Session{
+		perspective:    p,
+		connectionID:   connectionID,
+		transport:      t,
+		streams:        make(map[uint32]*Stream),
+		closingStreams: make(map[uint32]*Stream),
+		acceptSignal:   make(chan struct{}, 1),
+		openSignal:     make(chan struct{}, 1),
+		closed:         make(chan struct{}),
+		flow:           newFlowController(initialConnectionWindow, initialConnectionWindow),
+		idleTimeout:    defaultIdleTimeout,
+		roundTripTime:  initialRoundTripTime,
+		versions:       SupportedVersions(),
+		version:        versionRegistry[0].Version,
+	}
+	if p == packet.PerspectiveClient {
+		s.nextStreamID = firstClientStreamID
+		s.closedPeerStreams = newClosedStreamHistory(firstServerStreamID)
+	} else {
+		s.nextStreamID = firstServerStreamID
+		s.closedPeerStreams = newClosedStreamHistory(firstClientStreamID)
+	}
+	s.lastReceivedTime = time.Now()
+	s.idleTimer = time.AfterFunc(s.idleTimeout, s.checkIdle)
+	return s
+}
+
+// OpenStream opens a new stream. If the maximum number of open streams has been reached,
+// ErrTooManyOpenStreams is returned. If the session is going away, ErrGoAway is returned.
+func (s *Session) OpenStream() (*Stream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.openStream()
+}
+
+// OpenStreamSync opens a new stream. If the maximum number of open streams has been reached, it
+// blocks until another stream gets closed.
+func (s *Session) OpenStreamSync() (*Stream, error) {
+	for {
+		s.mu.Lock()
+		st, err := s.openStream()
+		s.mu.Unlock()
+		if err == nil {
+			// Wake up the next waiting caller, since more streams might have been closed.
+			signal(s.openSignal)
+		}
+		if err != ErrTooManyOpenStreams {
+			return st, err
+		}
+
+		select {
+		case <-s.openSignal:
+		case <-s.closed:
+		}
+	}
+}
+
+// AcceptStream waits for and returns the next stream opened by the peer.
+func (s *Session) AcceptStream() (*Stream, error) {
+	for {
+		s.mu.Lock()
+		if len(s.acceptQueue) > 0 {
+			st := s.acceptQueue[0]
+			s.acceptQueue = s.acceptQueue[1:]
+			if len(s.acceptQueue) > 0 {
+				signal(s.acceptSignal)
+			}
+			s.mu.Unlock()
+			return st, nil
+		}
+		if s.err != nil {
+			err := s.err
+			s.mu.Unlock()
+			return nil, err
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.acceptSignal:
+		case <-s.closed:
+		}
+	}
+}
+
+// Close closes the session and all its streams. The peer is notified with the NoError code.
+func (s *Session) Close() error {
+	s.sendConnectionClose(NoError, "")
+	s.closeWithError(ErrClosed)
+	return nil
+}
+
+// CloseWithError closes the session and all its streams. The peer is notified with the provided
+// error code and reason phrase. Subsequent operations return a ConnectionError.
+func (s *Session) CloseWithError(code ErrorCode, reasonPhrase string) error {
+	s.sendConnectionClose(code, reasonPhrase)
+	s.closeWithError(&ConnectionError{ErrorCode: code, ReasonPhrase: reasonPhrase})
+	return nil
+}
+
+// GoAway starts draining the session. The peer is notified that no new streams are accepted, while
+// the open streams can be finished. Once all streams have been closed, the session gets closed.
+func (s *Session) GoAway() error {
+	s.mu.Lock()
+	if s.err != nil {
+		err := s.err
+		s.mu.Unlock()
+		return err
+	}
+	if s.goAwaySent {
+		s.mu.Unlock()
+		return nil
+	}
+	s.goAwaySent = true
+	lastGoodStreamID := s.highestPeerStreamID
+	drained := len(s.streams) == 0
+	s.mu.Unlock()
+
+	if drained {
+		return s.Close()
+	}
+	return s.sendGoAway(PeerGoingAway, lastGoodStreamID)
+}
+
+// SetIdleTimeout sets the time after which the session is closed, if no packet has been received.
+// The session is then closed with the NetworkIdleTimeout code. A zero or negative timeout disables
+// the idle timeout and with it the keep alive pings. The default is 30 seconds.
+func (s *Session) SetIdleTimeout(timeout time.Duration) error {
+	s.mu.Lock()
+	s.idleTimeout = timeout
+	s.mu.Unlock()
+
+	s.idleTimer.Reset(0)
+	return nil
+}
+
+// SetKeepAlive enables or disables keep alive. If enabled, a ping is sent after half of the idle
+// timeout without a received packet, so the session and the bindings of NATs on the way stay open.
+func (s *Session) SetKeepAlive(keepAlive bool) error {
+	s.mu.Lock()
+	s.keepAlive = keepAlive
+	s.mu.Unlock()
+
+	s.idleTimer.Reset(0)
+	return nil
+}
+
+// Version returns the quic version of the session. Until a client session has received the first
+// packet from the server, the version might still change due to a version negotiation.
+func (s *Session) Version() Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// SetVersions sets the quic versions offered by a client session in descending order of
+// preference. The first version is used for the first packet. If the server doesn't support it,
+// the best common version is negotiated. The default are all versions supported by this
+// implementation. If a version isn't supported, ErrUnsupportedVersion is returned. Once the
+// first packet has been sent, ErrVersionInUse is returned.
+func (s *Session) SetVersions(versions []Version) error {
+	versions, err := checkVersions(versions)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.packetNumber > 0 {
+		return ErrVersionInUse
+	}
+	s.versions = versions
+	s.version = versions[0]
+	return nil
+}
+
+// LocalAddr returns the local network address.
+func (s *Session) LocalAddr() net.Addr {
+	return s.transport.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (s *Session) RemoteAddr() net.Addr {
+	return s.transport.RemoteAddr()
+}
+
+func (s *Session) openStream() (*Stream, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.goAwaySent || s.goAwayReceived {
+		return nil, ErrGoAway
+	}
+	if len(s.streams) >= maxOpenStreams {
+		return nil, ErrTooManyOpenStreams
+	}
+
+	st := newStream(s.nextStreamID, s)
+	s.streams[st.id] = st
+	s.nextStreamID += 2
+	return st, nil
+}
+
+// openStreamWithID opens the stream with the provided id. If the stream is already open, it's
+// returned.
+func (s *Session) openStreamWithID(id uint32) *Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.streams[id]
+	if !ok {
+		st = newStream(id, s)
+		s.streams[id] = st
+		if s.isPeerStreamID(id) && id > s.highestPeerStreamID {
+			s.highestPeerStreamID = id
+		}
+	}
+	return st
+}
+
+// peerStream returns the stream with the provided id for a received frame. A stream opened by the
+// peer is created together with the peer's lower streams that haven't been opened yet, since their
+// first frames might have been reordered or lost. The new streams are queued for AcceptStream in
+// ascending order. If the stream has already been closed, nil is returned. If the session is going
+// away, new streams are refused with ErrGoAway. If the peer exceeds the maximal number of open
+// streams, ErrTooManyOpenStreams is returned.
+func (s *Session) peerStream(id uint32) (*Stream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st, ok := s.streams[id]; ok {
+		return st, nil
+	}
+	if st, ok := s.closingStreams[id]; ok {
+		return st, nil
+	}
+	if !s.isPeerStreamID(id) || s.closedPeerStreams.isClosed(id) || s.err != nil {
+		return nil, nil
+	}
+	if s.goAwaySent {
+		return nil, ErrGoAway
+	}
+
+	next := s.highestPeerStreamID + 2
+	if s.highestPeerStreamID == 0 {
+		next = s.closedPeerStreams.closedBelow
+	}
+	if id < next {
+		// The stream is below a stream that has been opened with openStreamWithID.
+		next = id
+	}
+	ids := []uint32{}
+	for ; ; next += 2 {
+		if _, ok := s.streams[next]; !ok && !s.closedPeerStreams.isClosed(next) {
+			if len(s.streams)+len(ids) >= maxOpenStreams {
+				return nil, ErrTooManyOpenStreams
+			}
+			ids = append(ids, next)
+		}
+		if next == id {
+			break
+		}
+	}
+
+	for _, next := range ids {
+		s.streams[next] = newStream(next, s)
+		s.acceptQueue = append(s.acceptQueue, s.streams[next])
+	}
+	if id > s.highestPeerStreamID {
+		s.highestPeerStreamID = id
+	}
+	signal(s.acceptSignal)
+	return s.streams[id], nil
+}
+
+// removeStream removes the stream with the provided id from the session. If the session is going
+// away and the last stream has been removed, the session gets closed.
+func (s *Session) removeStream(id uint32) {
+	s.retireStream(id, nil)
+}
+
+// abandonStream removes the provided stream like removeStream, but keeps it as closing stream until
+// the peer's final offset is known, so the data that is still in flight is counted for the
+// connection's flow control window.
+func (s *Session) abandonStream(st *Stream) {
+	s.retireStream(st.id, st)
+}
+
+func (s *Session) retireStream(id uint32, closing *Stream) {
+	s.mu.Lock()
+	delete(s.streams, id)
+	delete(s.closingStreams, id)
+	if closing != nil && s.err == nil {
+		s.closingStreams[id] = closing
+	}
+	if s.isPeerStreamID(id) {
+		s.closedPeerStreams.close(id)
+	}
+	drained := s.goAwaySent && len(s.streams) == 0 && s.err == nil
+	s.mu.Unlock()
+	signal(s.openSignal)
+
+	if drained {
+		s.Close()
+	}
+}
+
+func (s *Session) peerPerspective() packet.Perspective {
+	if s.perspective == packet.PerspectiveClient {
+		return packet.PerspectiveServer
+	}
+	return packet.PerspectiveClient
+}
+
+func (s *Session) isPeerStreamID(id uint32) bool {
+	if s.perspective == packet.PerspectiveClient {
+		return id%2 == 0
+	}
+	return id%2 == 1
+}
+
+// handlePacket processes a received datagram. Malformed packets are dropped.
+func (s *Session) handlePacket(b []byte) {
+	p, err := packet.Parse(b, s.peerPerspective())
+	if err != nil {
+		return
+	}
+
+	switch p := p.(type) {
+	case packet.PublicReset:
+		s.handlePublicReset(p)
+	case packet.VersionNegotiation:
+		s.handleVersionNegotiation(p)
+	case packet.Regular:
+		s.handleRegular(p, nil)
+	}
+}
+
+// handleRegular processes a regular packet that has been received from the provided address. The
+// peer's address might change, e.g. due to a NAT rebinding. To prevent spoofed or replayed packets
+// from redirecting the session, only a packet that raises the largest received packet number moves
+// the session to its address. Client sessions pass a nil address, since they can't migrate.
+func (s *Session) handleRegular(r packet.Regular, addr net.Addr) {
+	s.mu.Lock()
+	if packet.Header(r).Flags()&packet.FlagVersion != 0 && r.Version() != s.version {
+		s.mu.Unlock()
+		return
+	}
+	packetNumber := packet.ExpandPacketNumber(r.PacketNumber(), r.PacketNumberLen(), s.largestReceived)
+	if !s.receivedPackets.receivedPacket(packetNumber) {
+		// Duplicates are dropped.
+		s.mu.Unlock()
+		return
+	}
+	largest := packetNumber > s.largestReceived || !s.receivedPacket
+	if largest {
+		s.largestReceived = packetNumber
+		s.largestReceivedTime = time.Now()
+	}
+	s.receivedPacket = true
+	s.unconfirmedPayloads = nil
+	s.lastReceivedTime = time.Now()
+	s.mu.Unlock()
+
+	if pct, ok := s.transport.(*packetConnTransport); ok && largest && addr != nil {
+		pct.setRemoteAddr(addr)
+	}
+
+	if retransmittable := s.handleFrames(r, packetNumber); retransmittable {
+		s.scheduleAck()
+	}
+}
+
+// handlePublicReset closes the session, if the public reset rejects a packet that has been sent by
+// the session. Other public resets are ignored, since they can't refer to this session.
+func (s *Session) handlePublicReset(pr packet.PublicReset) {
+	s.mu.Lock()
+	valid := pr.ConnectionID() == s.connectionID && pr.RejectedPacketNumber() <= s.packetNumber
+	s.mu.Unlock()
+	if !valid {
+		return
+	}
+
+	s.closeWithError(ErrPublicReset)
+}
+
+func (s *Session) handleVersionNegotiation(vn packet.VersionNegotiation) {
+	s.mu.Lock()
+	// The version can only be negotiated before the first packet of the server has been received
+	// and only once. Packets that offer the version in use have been delayed or spoofed, since the
+	// server would have accepted it. To protect against downgrade attacks by spoofed packets, the
+	// originally offered version is sent to the server, which checks that it doesn't support it.
+	versions := vn.Versions()
+	if s.receivedPacket || vn.ConnectionID() != s.connectionID || containsVersion(versions, s.version) {
+		s.mu.Unlock()
+		return
+	}
+	version, ok := selectVersion(s.versions, versions)
+	if !ok || s.versionNegotiated {
+		s.mu.Unlock()
+		s.closeWithError(ErrVersionNegotiation)
+		return
+	}
+	offered := s.version
+	s.version = version
+	s.versionNegotiated = true
+	payloads := s.unconfirmedPayloads
+	s.unconfirmedPayloads = nil
+	// The payloads are sent again below, so the dropped packets don't have to be retransmitted.
+	s.sentPackets = sentPacketHistory{}
+	s.mu.Unlock()
+
+	// The server has dropped the packets sent so far, so they are sent again with the new version.
+	for _, payload := range payloads {
+		s.sendPacket(payload)
+	}
+	s.sendOfferedVersion(offered)
+}
+
+// sendOfferedVersion sends a client hello with the provided version on the crypto stream, so the
+// server can detect a downgrade of the version.
+func (s *Session) sendOfferedVersion(version Version) error {
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, uint32(version))
+
+	message := handshake.NewMessage(handshake.TagCHLO)
+	message.Set(handshake.TagVER, value)
+	data, err := message.Marshal()
+	if err != nil {
+		return err
+	}
+	return s.sendStreamFrame(cryptoStreamID, 0, data, false)
+}
+
+// handleFrames processes the frames of the provided packet's payload. It returns true, if the packet
+// contained frames that have to be acknowledged. If a frame is malformed, the session is closed
+// with the corresponding error code.
+func (s *Session) handleFrames(r packet.Regular, packetNumber uint64) bool {
+	retransmittable := false
+	it := frame.NewIterator(r.Data(), r.PacketNumberLen())
+	for it.Next() {
+		switch f := it.Frame().(type) {
+		case frame.Stream:
+			s.handleStreamFrame(f)
+		case frame.Acknowledge:
+			s.handleAcknowledgeFrame(f)
+		case frame.ResetStream:
+			s.handleResetStreamFrame(f)
+		case frame.ConnectionClose:
+			s.closeWithError(&ConnectionError{
+				ErrorCode:    ErrorCode(f.ErrorCode()),
+				ReasonPhrase: f.ReasonPhrase(),
+				Remote:       true,
+			})
+			return false
+		case frame.GoAway:
+			s.handleGoAwayFrame(f)
+		case frame.WindowUpdate:
+			s.handleWindowUpdateFrame(f)
+		case frame.Blocked:
+			s.handleBlockedFrame(f)
+		case frame.StopWaiting:
+			s.handleStopWaitingFrame(f, packetNumber, r.PacketNumberLen())
+		}
+
+		switch it.Type() {
+		case frame.TypeAcknowledge, frame.TypeStopWaiting, frame.TypePadding:
+		default:
+			retransmittable = true
+		}
+	}
+	if it.Err() != nil {
+		s.CloseWithError(invalidFrameErrorCode(it.Type()), "")
+		return false
+	}
+	return retransmittable
+}
+
+// invalidFrameErrorCode returns the error code for a malformed frame of the provided type.
+func invalidFrameErrorCode(frameType uint8) ErrorCode {
+	switch frameType {
+	case frame.TypeStream:
+		return InvalidStreamData
+	case frame.TypeAcknowledge:
+		return InvalidAckData
+	case frame.TypeResetStream:
+		return InvalidRstStreamData
+	case frame.TypeConnectionClose:
+		return InvalidConnectionCloseData
+	case frame.TypeGoAway:
+		return InvalidGoawayData
+	case frame.TypeWindowUpdate:
+		return InvalidWindowUpdateData
+	case frame.TypeBlocked:
+		return InvalidBlockedData
+	case frame.TypeStopWaiting:
+		return InvalidStopWaitingData
+	}
+	return InvalidFrameData
+}
+
+// handleAcknowledgeFrame records the largest packet number acknowledged by the peer, so shorter
+// packet numbers can be sent. The acknowledged packets are removed from the sent packet history and
+// the packets that are considered lost are sent again.
+func (s *Session) handleAcknowledgeFrame(ack frame.Acknowledge) {
+	s.mu.Lock()
+	largestAcked := ack.LargestAcked()
+	if largestAcked > s.packetNumber {
+		s.mu.Unlock()
+		s.CloseWithError(InvalidAckData, "")
+		return
+	}
+	if largestAcked > s.largestAcked {
+		s.largestAcked = largestAcked
+	}
+	acked, sentTime := s.sentPackets.acknowledge(ack.AckRanges())
+	if !sentTime.IsZero() {
+		s.updateRoundTripTime(time.Since(sentTime), ack.AckDelay())
+	}
+	if acked > 0 {
+		s.retransmissions = 0
+		s.resetRetransmitTimer()
+	}
+	lost := s.sentPackets.lostPackets(s.largestAcked, time.Time{})
+	s.mu.Unlock()
+
+	s.retransmit(lost)
+}
+
+// updateRoundTripTime adds the provided sample, reduced by the peer's ack delay, to the smoothed
+// round trip time. It has to be called with the session's mutex held.
+func (s *Session) updateRoundTripTime(sample, ackDelay time.Duration) {
+	if sample > ackDelay {
+		sample -= ackDelay
+	}
+	s.roundTripTime = (7*s.roundTripTime + sample) / 8
+}
+
+// retransmissionTimeout returns the current retransmission timeout. It has to be called with the
+// session's mutex held.
+func (s *Session) retransmissionTimeout() time.Duration {
+	timeout := 2 * s.roundTripTime
+	if timeout < minRetransmissionTimeout {
+		timeout = minRetransmissionTimeout
+	}
+	for i := 0; i < s.retransmissions && timeout < maxRetransmissionTimeout; i++ {
+		timeout *= 2
+	}
+	if timeout > maxRetransmissionTimeout {
+		timeout = maxRetransmissionTimeout
+	}
+	return timeout
+}
+
+// resetRetransmitTimer schedules the retransmission timer for the oldest unacknowledged packet or
+// stops it, if all packets have been acknowledged. It has to be called with the session's mutex
+// held.
+func (s *Session) resetRetransmitTimer() {
+	if s.retransmitTimer != nil {
+		s.retransmitTimer.Stop()
+		s.retransmitTimer = nil
+	}
+	oldest, ok := s.sentPackets.oldestSentTime()
+	if !ok || s.err != nil {
+		return
+	}
+	timeout := time.Until(oldest.Add(s.retransmissionTimeout()))
+	s.retransmitTimer = time.AfterFunc(timeout, s.checkRetransmission)
+}
+
+// checkRetransmission sends the packets again that haven't been acknowledged within the
+// retransmission timeout.
+func (s *Session) checkRetransmission() {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return
+	}
+	lost := s.sentPackets.lostPackets(0, time.Now().Add(-s.retransmissionTimeout()))
+	if len(lost) > 0 {
+		s.retransmissions++
+	}
+	s.resetRetransmitTimer()
+	s.mu.Unlock()
+
+	s.retransmit(lost)
+}
+
+// retransmit sends the payloads of the lost packets again in new packets.
+func (s *Session) retransmit(lost []sentPacket) {
+	for _, p := range lost {
+		if err := s.sendPacket(p.payload); err != nil {
+			return
+		}
+	}
+}
+
+// handleStopWaitingFrame stops acknowledging the packets the peer isn't waiting for anymore.
+func (s *Session) handleStopWaitingFrame(sw frame.StopWaiting, packetNumber uint64, packetNumberLen int) {
+	delta := sw.LeastUnackedDelta(packetNumberLen)
+	if delta > packetNumber {
+		s.CloseWithError(InvalidStopWaitingData, "")
+		return
+	}
+
+	s.mu.Lock()
+	s.receivedPackets.ignoreBelow(packetNumber - delta)
+	s.mu.Unlock()
+}
+
+func (s *Session) handleStreamFrame(sf frame.Stream) {
+	if sf.StreamID() == cryptoStreamID {
+		s.handleCryptoStreamFrame(sf)
+		return
+	}
+	st, err := s.peerStream(sf.StreamID())
+	switch err {
+	case ErrTooManyOpenStreams:
+		s.CloseWithError(TooManyOpenStreams, "")
+		return
+	case ErrGoAway:
+		// The peer might have missed the go away frame, so the stream is reset explicitly.
+		s.writeStreamReset(sf.StreamID(), sf.Offset()+uint64(len(sf.Data())), uint32(PeerGoingAway))
+		return
+	}
+	if st == nil {
+		return
+	}
+	if ok := st.handleData(sf.Offset(), sf.Data(), sf.Fin()); !ok {
+		s.CloseWithError(FlowControlReceivedTooMuchData, "")
+	}
+}
+
+// handleCryptoStreamFrame checks the version in the client hello on the crypto stream, which is the
+// version the client has offered first. If the server supports that version, the version
+// negotiation has been tampered with and the session is closed with VersionNegotiationMismatch.
+// Since the crypto handshake isn't implemented, other messages are ignored.
+func (s *Session) handleCryptoStreamFrame(sf frame.Stream) {
+	if s.perspective != packet.PerspectiveServer || sf.Offset() != 0 {
+		return
+	}
+	message := handshake.Message{}
+	if err := message.Unmarshal(sf.Data()); err != nil || message.Tag != handshake.TagCHLO {
+		return
+	}
+	value, ok := message.Get(handshake.TagVER)
+	if !ok || len(value) != 4 {
+		return
+	}
+	offered := Version(binary.BigEndian.Uint32(value))
+
+	s.mu.Lock()
+	mismatch := offered != s.version && containsVersion(s.versions, offered)
+	s.mu.Unlock()
+
+	if mismatch {
+		s.CloseWithError(VersionNegotiationMismatch, "")
+	}
+}
+
+// handleResetStreamFrame aborts the reset stream. Since a reset carries no data, it doesn't open
+// streams. A peer stream that hasn't been opened yet is recorded as closed instead.
+func (s *Session) handleResetStreamFrame(rs frame.ResetStream) {
+	var ok bool
+	if st := s.stream(rs.StreamID()); st != nil {
+		ok = st.handleReset(rs.ErrorCode(), rs.ByteOffset())
+	} else {
+		ok = s.handleUnopenedStreamReset(rs.StreamID(), rs.ByteOffset())
+	}
+	if !ok {
+		s.CloseWithError(FlowControlReceivedTooMuchData, "")
+	}
+}
+
+// handleUnopenedStreamReset records the peer stream with the provided id as closed, so it won't be
+// opened by later frames. The data up to the final offset is consumed from the connection's flow
+// control window. If the final offset exceeds the initial stream window or the connection's
+// window, false is returned.
+func (s *Session) handleUnopenedStreamReset(id uint32, finalOffset uint64) bool {
+	s.mu.Lock()
+	if !s.isPeerStreamID(id) || s.closedPeerStreams.isClosed(id) || s.err != nil {
+		s.mu.Unlock()
+		return true
+	}
+	s.closedPeerStreams.close(id)
+	s.mu.Unlock()
+
+	if finalOffset > initialStreamWindow || !s.addBytesReceived(finalOffset) {
+		return false
+	}
+	s.addBytesRead(finalOffset)
+	return true
+}
+
+// handleWindowUpdateFrame raises the send window of the stream or, for stream id zero, of the
+// connection and wakes up the blocked writers.
+func (s *Session) handleWindowUpdateFrame(wu frame.WindowUpdate) {
+	if id := wu.StreamID(); id != 0 {
+		if st := s.stream(id); st != nil {
+			st.handleWindowUpdate(wu.ByteOffset())
+		}
+		return
+	}
+
+	s.mu.Lock()
+	raised := s.flow.updateSendWindow(wu.ByteOffset())
+	streams := make([]*Stream, 0, len(s.streams))
+	for _, st := range s.streams {
+		streams = append(streams, st)
+	}
+	s.mu.Unlock()
+
+	if raised {
+		for _, st := range streams {
+			st.signalWrite()
+		}
+	}
+}
+
+// handleBlockedFrame answers with the current receive window, since the peer might have missed the
+// last window update.
+func (s *Session) handleBlockedFrame(bl frame.Blocked) {
+	if id := bl.StreamID(); id != 0 {
+		if st := s.stream(id); st != nil {
+			s.sendWindowUpdate(id, st.receiveWindow())
+		}
+		return
+	}
+
+	s.mu.Lock()
+	window := s.flow.receiveWindow
+	s.mu.Unlock()
+	s.sendWindowUpdate(0, window)
+}
+
+// stream returns the open stream with the provided id or nil.
+func (s *Session) stream(id uint32) *Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.streams[id]; ok {
+		return st
+	}
+	return s.closingStreams[id]
+}
+
+// reserveSendWindow reserves up to n bytes of the connection's send window and returns the number
+// of reserved bytes. If the window is exhausted, a blocked frame is sent.
+func (s *Session) reserveSendWindow(n uint64) uint64 {
+	s.mu.Lock()
+	if window := s.flow.sendWindowSize(); n > window {
+		n = window
+	}
+	s.flow.addBytesSent(n)
+	blocked := n == 0 && s.flow.shouldSendBlocked()
+	s.mu.Unlock()
+
+	if blocked {
+		s.sendBlocked(0)
+	}
+	return n
+}
+
+// addBytesReceived adds n received bytes to the connection's receive window. If the window is
+// exceeded, false is returned.
+func (s *Session) addBytesReceived(n uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.flow.updateBytesReceived(s.flow.bytesReceived + n)
+	return ok
+}
+
+// addBytesRead adds n consumed bytes to the connection's receive window and sends a window update,
+// if needed.
+func (s *Session) addBytesRead(n uint64) {
+	if n == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	window, update := s.flow.addBytesRead(n)
+	s.mu.Unlock()
+
+	if update {
+		s.sendWindowUpdate(0, window)
+	}
+}
+
+// handleGoAwayFrame prevents new streams from being opened. Streams that have been opened after the
+// peer's last good stream are closed with ErrGoAway, since the peer won't process them.
+func (s *Session) handleGoAwayFrame(ga frame.GoAway) {
+	lastGoodStreamID := ga.LastGoodStreamID()
+
+	s.mu.Lock()
+	s.goAwayReceived = true
+	rejected := []*Stream{}
+	for id, st := range s.streams {
+		if !s.isPeerStreamID(id) && id > lastGoodStreamID {
+			rejected = append(rejected, st)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, st := range rejected {
+		st.closeWithError(ErrGoAway)
+		s.removeStream(st.id)
+	}
+}
+
+// writeStreamFin sends a frame with the fin flag on the stream with the provided id.
+func (s *Session) writeStreamFin(id uint32, offset uint64) error {
+	return s.sendStreamFrame(id, offset, nil, true)
+}
+
+// writeStreamReset sends a reset stream frame with the provided final offset and error code on the
+// stream with the provided id.
+func (s *Session) writeStreamReset(id uint32, offset uint64, code uint32) error {
+	rs := frame.ResetStream(make([]byte, 1+4+8+4))
+	rs.SetStreamID(id)
+	rs.SetByteOffset(offset)
+	rs.SetErrorCode(code)
+
+	return s.sendPacket(rs)
+}
+
+func (s *Session) sendConnectionClose(code ErrorCode, reasonPhrase string) error {
+	// The reason phrase is truncated to fit into a single packet.
+	if max := MaxPacketSize - (1 + 8 + 4 + 6) - (1 + 4 + 2); len(reasonPhrase) > max {
+		reasonPhrase = reasonPhrase[:max]
+	}
+
+	cc := frame.ConnectionClose(make([]byte, 1+4+2+len(reasonPhrase)))
+	cc.SetErrorCode(uint32(code))
+	cc.SetReasonPhrase(reasonPhrase)
+
+	return s.sendPacket(cc)
+}
+
+// checkIdle closes the session, if no packet has been received within the idle timeout. If keep
+// alive is enabled, a ping is sent after half of the idle timeout. The idle timer is rescheduled
+// for the next check, unless the idle timeout is disabled.
+func (s *Session) checkIdle() {
+	s.mu.Lock()
+	if s.err != nil || s.idleTimeout <= 0 {
+		s.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	idle := now.Sub(s.lastReceivedTime)
+	if idle >= s.idleTimeout {
+		s.mu.Unlock()
+		s.CloseWithError(NetworkIdleTimeout, "no recent network activity")
+		return
+	}
+
+	next := s.idleTimeout - idle
+	ping := false
+	if s.keepAlive {
+		interval := s.idleTimeout / 2
+		sincePing := now.Sub(s.lastPingTime)
+		if idle >= interval && sincePing >= interval {
+			ping = true
+			s.lastPingTime = now
+			sincePing = 0
+		}
+		untilPing := interval - idle
+		if idle >= interval {
+			untilPing = interval - sincePing
+		}
+		if untilPing < next {
+			next = untilPing
+		}
+	}
+	s.idleTimer.Reset(next)
+	s.mu.Unlock()
+
+	if ping {
+		s.sendPing()
+	}
+}
+
+// scheduleAck acknowledges the received packets immediately, if enough retransmittable packets have
+// been received, or starts the ack timer otherwise.
+func (s *Session) scheduleAck() {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.ackPending++
+	if s.ackPending < ackPacketThreshold {
+		if s.ackTimer == nil {
+			s.ackTimer = time.AfterFunc(ackDelay, func() { s.sendAck() })
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	s.sendAck()
+}
+
+// sendAck sends an acknowledge frame for the received packets.
+func (s *Session) sendAck() error {
+	s.mu.Lock()
+	if s.ackTimer != nil {
+		s.ackTimer.Stop()
+		s.ackTimer = nil
+	}
+	s.ackPending = 0
+	ranges := s.receivedPackets.ackRanges()
+	if len(ranges) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	blockLen := 1
+	for _, r := range ranges {
+		if l := frame.MinAckLen(r.Largest - r.Smallest + 1); l > blockLen {
+			blockLen = l
+		}
+	}
+	ack := frame.Acknowledge(make([]byte, maxAckFrameLen))
+	ack.SetLargestAcked(ranges[0].Largest, frame.MinAckLen(ranges[0].Largest))
+	ack.SetAckDelay(time.Since(s.largestReceivedTime))
+	ack.SetAckRanges(ranges, blockLen)
+	s.mu.Unlock()
+
+	return s.sendPacket(ack[:ack.Len()])
+}
+
+func (s *Session) sendPing() error {
+	ping := frame.Ping(make([]byte, 1))
+	ping.Set()
+
+	return s.sendPacket(ping)
+}
+
+func (s *Session) sendWindowUpdate(id uint32, offset uint64) error {
+	wu := frame.WindowUpdate(make([]byte, 1+4+8))
+	wu.SetStreamID(id)
+	wu.SetByteOffset(offset)
+
+	return s.sendPacket(wu)
+}
+
+func (s *Session) sendBlocked(id uint32) error {
+	bl := frame.Blocked(make([]byte, 1+4))
+	bl.SetStreamID(id)
+
+	return s.sendPacket(bl)
+}
+
+func (s *Session) sendGoAway(code ErrorCode, lastGoodStreamID uint32) error {
+	ga := frame.GoAway(make([]byte, 1+4+4+2))
+	ga.SetErrorCode(uint32(code))
+	ga.SetLastGoodStreamID(lastGoodStreamID)
+	ga.SetReasonPhrase("")
+
+	return s.sendPacket(ga)
+}
+
+func (s *Session) sendStreamFrame(id uint32, offset uint64, data []byte, fin bool) error {
+	b, packetNumber, err := s.newPacketBuilder()
+	if err != nil {
+		return err
+	}
+	// The data is limited by maxStreamDataLen, so it always fits into a single packet.
+	b.AppendStreamFrame(id, offset, data, fin)
+
+	return s.writePacket(b, packetNumber)
+}
+
+func (s *Session) sendPacket(payload []byte) error {
+	b, packetNumber, err := s.newPacketBuilder()
+	if err != nil {
+		return err
+	}
+	b.AppendFrame(payload)
+
+	return s.writePacket(b, packetNumber)
+}
+
+// writePacket sends the built packet with the provided packet number. Retransmittable payloads are
+// recorded in the sent packet history until they are acknowledged. Until a client session has
+// received the first packet from the server, the payloads are kept, so they can be sent again after
+// a version negotiation.
+func (s *Session) writePacket(b *packet.Builder, packetNumber uint64) error {
+	r := b.Packet()
+
+	s.mu.Lock()
+	if s.perspective == packet.PerspectiveClient && !s.receivedPacket {
+		payload := append([]byte(nil), r.Data()...)
+		if r.Version() != s.version {
+			// The version has been negotiated while the packet has been built.
+			s.mu.Unlock()
+			return s.sendPacket(payload)
+		}
+		s.unconfirmedPayloads = append(s.unconfirmedPayloads, payload)
+	}
+	if isRetransmittable(r.Data()) {
+		s.sentPackets.sentPacket(packetNumber, r.Data(), time.Now())
+		if s.retransmitTimer == nil {
+			s.resetRetransmitTimer()
+		}
+	}
+	s.mu.Unlock()
+
+	return s.transport.WritePacket(r)
+}
+
+// newPacketBuilder returns a builder for the next packet with the header fields already set
+// together with the packet number.
+func (s *Session) newPacketBuilder() (*packet.Builder, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	s.packetNumber++
+	packetNumberLen := packet.PacketNumberLen(s.packetNumber, s.largestAcked)
+
+	b := packet.NewBuilder(MaxPacketSize)
+	b.SetConnectionID(s.connectionID)
+	if s.perspective == packet.PerspectiveClient && !s.receivedPacket {
+		b.SetVersion(s.version)
+	}
+	b.SetPacketNumber(packet.TruncatePacketNumber(s.packetNumber, packetNumberLen), packetNumberLen)
+	return b, s.packetNumber, nil
+}
+
+func (s *Session) maxStreamDataLen() int {
+	return MaxPacketSize - (1 + 8 + 4 + 6) - streamFrameOverhead
+}
+
+func (s *Session) closeWithError(err error) {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.err = err
+	if s.ackTimer != nil {
+		s.ackTimer.Stop()
+		s.ackTimer = nil
+	}
+	if s.retransmitTimer != nil {
+		s.retransmitTimer.Stop()
+		s.retransmitTimer = nil
+	}
+	s.idleTimer.Stop()
+	streams := make([]*Stream, 0, len(s.streams))
+	for _, st := range s.streams {
+		streams = append(streams, st)
+	}
+	close(s.closed)
+	s.mu.Unlock()
+
+	for _, st := range streams {
+		st.closeWithError(err)
+	}
+	s.transport.Close()
+}
+
+// streamFrameOverhead defines the number of bytes a stream frame with 4 byte stream id, 8 byte
+// offset and a data length field adds to the data.
+const streamFrameOverhead = 1 + 4 + 8 + 2
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+func newConnectionID() (uint64, error) {
+	return randomUint64()
+}
+
+func randomUint64() (uint64, error) {
+	buffer := make([]byte, 8)
+	if _, err := rand.Read(buffer); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buffer), nil
+}
@@ -0,0 +1,488 @@
+package quic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrStreamClosed is returned by Read and Write if the stream has been closed.
+var ErrStreamClosed = errors.New("quic: stream closed")
+
+// ErrTimeout is returned by Read and Write if the corresponding deadline has been exceeded.
+var ErrTimeout error = &timeoutError{}
+
+type timeoutError struct{}
+
+func (*timeoutError) Error() string   { return "quic: i/o timeout" }
+func (*timeoutError) Timeout() bool   { return true }
+func (*timeoutError) Temporary() bool { return true }
+
+// StreamCancelled is the error code of the reset stream frame that is sent, if a stream is closed
+// before all data of the peer has been received or if the stream has been reset by the peer while
+// its write side was still open (QUIC_STREAM_CANCELLED).
+const StreamCancelled uint32 = 6
+
+// StreamResetError is returned by Read and Write if the peer has reset the stream.
+type StreamResetError struct {
+	StreamID  uint32
+	ErrorCode uint32
+}
+
+func (e *StreamResetError) Error() string {
+	return fmt.Sprintf("quic: stream %d reset by peer with error code %d", e.StreamID, e.ErrorCode)
+}
+
+// Stream defines a single stream of a session. It implements net.Conn.
+type Stream struct {
+	id      uint32
+	session *Session
+
+	// writeMu serializes writes, so the data of concurrent writes doesn't get interleaved.
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	readOffset    uint64
+	readBuffer    []byte
+	pending       map[uint64][]byte
+	readSignal    chan struct{}
+	readDeadline  time.Time
+	finReceived   bool
+	finOffset     uint64
+	writeOffset   uint64
+	writeSignal   chan struct{}
+	writeDeadline time.Time
+	writeClosed   bool
+	flow          flowController
+	err           error
+}
+
+var _ net.Conn = &Stream{}
+
+func newStream(id uint32, s *Session) *Stream {
+	return &Stream{
+		id:          id,
+		session:     s,
+		pending:     make(map[uint64][]byte),
+		readSignal:  make(chan struct{}, 1),
+		writeSignal: make(chan struct{}, 1),
+		flow:        newFlowController(initialStreamWindow, initialStreamWindow),
+	}
+}
+
+// StreamID returns the stream id.
+func (st *Stream) StreamID() uint32 {
+	return st.id
+}
+
+// Read reads data from the stream. The consumed data is returned to the peer's flow control
+// window. Once all data up to the peer's fin has been read, io.EOF is returned. If the stream or
+// the session gets closed before, the error is returned, even if the session has been closed
+// without an error.
+func (st *Stream) Read(b []byte) (int, error) {
+	for {
+		st.mu.Lock()
+		if len(st.readBuffer) > 0 {
+			n := copy(b, st.readBuffer)
+			st.readBuffer = st.readBuffer[n:]
+			st.readOffset += uint64(n)
+			window, update := st.flow.addBytesRead(uint64(n))
+			update = update && !st.finReceived
+			st.mu.Unlock()
+
+			if update {
+				st.session.sendWindowUpdate(st.id, window)
+			}
+			st.session.addBytesRead(uint64(n))
+			return n, nil
+		}
+		if st.finReceived && st.readOffset >= st.finOffset {
+			st.mu.Unlock()
+			return 0, io.EOF
+		}
+		if st.err != nil {
+			// Even if the session has been closed gracefully, the stream is truncated without fin.
+			err := st.err
+			st.mu.Unlock()
+			return 0, err
+		}
+		deadline := st.readDeadline
+		st.mu.Unlock()
+
+		if err := waitSignal(st.readSignal, deadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write writes data to the stream. The data is split into as many packets as needed. If the flow
+// control window of the stream or the connection is exhausted, Write blocks until the peer raises
+// it.
+func (st *Stream) Write(b []byte) (int, error) {
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+
+	written := 0
+	for {
+		st.mu.Lock()
+		if st.err != nil {
+			err := st.err
+			st.mu.Unlock()
+			return written, err
+		}
+		if st.writeClosed {
+			st.mu.Unlock()
+			return written, ErrStreamClosed
+		}
+		deadline := st.writeDeadline
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			st.mu.Unlock()
+			return written, ErrTimeout
+		}
+		if len(b) == 0 {
+			st.mu.Unlock()
+			return written, nil
+		}
+		offset := st.writeOffset
+		n := uint64(len(b))
+		if max := uint64(st.session.maxStreamDataLen()); n > max {
+			n = max
+		}
+		if window := st.flow.sendWindowSize(); n > window {
+			n = window
+		}
+		streamBlocked := n == 0 && st.flow.shouldSendBlocked()
+		st.mu.Unlock()
+
+		if n > 0 {
+			n = st.session.reserveSendWindow(n)
+		}
+		if n == 0 {
+			if streamBlocked {
+				st.session.sendBlocked(st.id)
+			}
+			if err := waitSignal(st.writeSignal, deadline); err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		st.mu.Lock()
+		st.writeOffset += n
+		st.flow.addBytesSent(n)
+		st.mu.Unlock()
+
+		if err := st.session.sendStreamFrame(st.id, offset, b[:n], false); err != nil {
+			return written, err
+		}
+		written += int(n)
+		b = b[n:]
+	}
+}
+
+// CloseWrite closes the write side of the stream and signals the end of the stream to the peer.
+// The peer's Read returns io.EOF once it has read all data. Subsequent writes return
+// ErrStreamClosed.
+func (st *Stream) CloseWrite() error {
+	st.mu.Lock()
+	if st.err != nil || st.writeClosed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.writeClosed = true
+	offset := st.writeOffset
+	finished := st.allDataReceived()
+	st.mu.Unlock()
+
+	err := st.session.writeStreamFin(st.id, offset)
+	if finished {
+		st.session.removeStream(st.id)
+	}
+	return err
+}
+
+// CancelWrite aborts the write side of the stream. Data that hasn't been delivered to the peer yet
+// might get lost. The peer's Read and Write return a StreamResetError with the provided code.
+// Subsequent writes return ErrStreamClosed. If the write side has already been closed,
+// CancelWrite does nothing.
+func (st *Stream) CancelWrite(code uint32) error {
+	st.mu.Lock()
+	if st.err != nil || st.writeClosed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.writeClosed = true
+	offset := st.writeOffset
+	finished := st.allDataReceived()
+	st.mu.Unlock()
+	st.signalWrite()
+
+	err := st.session.writeStreamReset(st.id, offset, code)
+	if finished {
+		st.session.removeStream(st.id)
+	}
+	return err
+}
+
+// Close closes the stream. If the peer's fin has been received, the write side is closed as with
+// CloseWrite. Otherwise, the stream is reset with StreamCancelled, so the peer stops sending. Data
+// that hasn't been read is dropped and returned to the connection's flow control window. Subsequent
+// reads and writes return ErrStreamClosed.
+func (st *Stream) Close() error {
+	st.mu.Lock()
+	if st.err != nil {
+		st.mu.Unlock()
+		st.session.removeStream(st.id)
+		return nil
+	}
+	st.err = ErrStreamClosed
+	writeClosed := st.writeClosed
+	st.writeClosed = true
+	offset := st.writeOffset
+	finReceived := st.finReceived
+	unread := st.flow.bytesReceived - st.readOffset
+	st.readBuffer = nil
+	st.pending = make(map[uint64][]byte)
+	st.mu.Unlock()
+	st.signalRead()
+	st.signalWrite()
+
+	var err error
+	if finReceived {
+		if !writeClosed {
+			err = st.session.writeStreamFin(st.id, offset)
+		}
+		st.session.removeStream(st.id)
+	} else {
+		err = st.session.writeStreamReset(st.id, offset, StreamCancelled)
+		st.session.abandonStream(st)
+	}
+	st.session.addBytesRead(unread)
+	return err
+}
+
+// LocalAddr returns the local network address.
+func (st *Stream) LocalAddr() net.Addr {
+	return st.session.transport.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address.
+func (st *Stream) RemoteAddr() net.Addr {
+	return st.session.transport.RemoteAddr()
+}
+
+// SetDeadline sets the read and write deadlines.
+func (st *Stream) SetDeadline(t time.Time) error {
+	st.SetReadDeadline(t)
+	st.SetWriteDeadline(t)
+	return nil
+}
+
+// SetReadDeadline sets the read deadline.
+func (st *Stream) SetReadDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.readDeadline = t
+	st.mu.Unlock()
+	st.signalRead()
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline.
+func (st *Stream) SetWriteDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.writeDeadline = t
+	st.mu.Unlock()
+	st.signalWrite()
+	return nil
+}
+
+// handleData adds the provided data at the provided offset to the stream's read buffer. Data that
+// arrives out of order is held back until the gap before it has been filled. If fin is true, the
+// data marks the end of the stream. If the data exceeds the flow control window of the stream or the
+// connection, false is returned.
+func (st *Stream) handleData(offset uint64, data []byte, fin bool) bool {
+	finished, increase, dropped, ok := st.receiveData(offset, data, fin)
+	if !ok || !st.session.addBytesReceived(increase) {
+		return false
+	}
+	st.session.addBytesRead(dropped)
+	if finished {
+		st.session.removeStream(st.id)
+	}
+	st.signalRead()
+	return true
+}
+
+// handleWindowUpdate raises the send window to the provided offset.
+func (st *Stream) handleWindowUpdate(offset uint64) {
+	st.mu.Lock()
+	raised := st.flow.updateSendWindow(offset)
+	st.mu.Unlock()
+
+	if raised {
+		st.signalWrite()
+	}
+}
+
+// receiveWindow returns the offset up to which the peer may send data.
+func (st *Stream) receiveWindow() uint64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.flow.receiveWindow
+}
+
+// handleReset aborts the stream after the peer has reset it. Data that hasn't been read yet is
+// dropped and returned to the connection's flow control window together with the data up to the
+// final offset that hasn't been received. If the write side is still open, the stream is reset in
+// turn with StreamCancelled, so the peer learns the final offset. If the final offset exceeds the
+// flow control window of the stream or the connection, false is returned.
+func (st *Stream) handleReset(code uint32, finalOffset uint64) bool {
+	st.mu.Lock()
+	increase, ok := st.flow.updateBytesReceived(finalOffset)
+	if !ok {
+		st.mu.Unlock()
+		return false
+	}
+	if st.err != nil {
+		// The stream has been closed already, so the remaining data is returned right away.
+		st.mu.Unlock()
+		if !st.session.addBytesReceived(increase) {
+			return false
+		}
+		st.session.addBytesRead(increase)
+		st.session.removeStream(st.id)
+		return true
+	}
+	unread := uint64(0)
+	if finalOffset > st.readOffset {
+		unread = finalOffset - st.readOffset
+	}
+	st.err = &StreamResetError{StreamID: st.id, ErrorCode: code}
+	writeClosed := st.writeClosed
+	st.writeClosed = true
+	offset := st.writeOffset
+	st.readBuffer = nil
+	st.pending = make(map[uint64][]byte)
+	st.mu.Unlock()
+
+	if !st.session.addBytesReceived(increase) {
+		return false
+	}
+	st.session.addBytesRead(unread)
+	if !writeClosed {
+		st.session.writeStreamReset(st.id, offset, StreamCancelled)
+	}
+	st.session.removeStream(st.id)
+	st.signalRead()
+	st.signalWrite()
+	return true
+}
+
+// receiveData adds the data to the read buffer. It returns whether both sides of the stream have
+// been closed, the increase of the received bytes, the number of received bytes that have been
+// dropped, because the stream has been closed, and whether the data is within the flow control
+// window.
+func (st *Stream) receiveData(offset uint64, data []byte, fin bool) (bool, uint64, uint64, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	increase, ok := st.flow.updateBytesReceived(offset + uint64(len(data)))
+	if !ok {
+		return false, 0, 0, false
+	}
+	if st.err != nil {
+		// The stream is finished, once the final offset is known.
+		return fin, increase, increase, true
+	}
+
+	if fin && !st.finReceived {
+		st.finReceived = true
+		st.finOffset = offset + uint64(len(data))
+	}
+	if len(data) == 0 {
+		return st.writeClosed && st.allDataReceived(), increase, 0, true
+	}
+
+	received := st.readOffset + uint64(len(st.readBuffer))
+	if offset > received {
+		if _, ok := st.pending[offset]; !ok {
+			st.pending[offset] = append([]byte(nil), data...)
+		}
+		return false, increase, 0, true
+	}
+	st.appendData(offset, data)
+
+	for len(st.pending) > 0 {
+		received = st.readOffset + uint64(len(st.readBuffer))
+		found := false
+		for pendingOffset, pendingData := range st.pending {
+			if pendingOffset <= received {
+				delete(st.pending, pendingOffset)
+				st.appendData(pendingOffset, pendingData)
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	return st.writeClosed && st.allDataReceived(), increase, 0, true
+}
+
+// allDataReceived returns true, if the peer's fin and all data before it have been received.
+func (st *Stream) allDataReceived() bool {
+	return st.finReceived && st.readOffset+uint64(len(st.readBuffer)) >= st.finOffset
+}
+
+func (st *Stream) appendData(offset uint64, data []byte) {
+	received := st.readOffset + uint64(len(st.readBuffer))
+	end := offset + uint64(len(data))
+	if end <= received {
+		return
+	}
+	st.readBuffer = append(st.readBuffer, data[received-offset:]...)
+}
+
+func (st *Stream) closeWithError(err error) {
+	st.mu.Lock()
+	if st.err == nil {
+		st.err = err
+	}
+	st.mu.Unlock()
+	st.signalRead()
+	st.signalWrite()
+}
+
+func (st *Stream) signalRead() {
+	signal(st.readSignal)
+}
+
+func (st *Stream) signalWrite() {
+	signal(st.writeSignal)
+}
+
+// waitSignal blocks until the signal channel fires or the deadline is exceeded.
+func waitSignal(signal <-chan struct{}, deadline time.Time) error {
+	if deadline.IsZero() {
+		<-signal
+		return nil
+	}
+
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return ErrTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-signal:
+		return nil
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
@@ -0,0 +1,74 @@
+package quic
+
+import (
+	"net"
+	"sync"
+)
+
+// transport defines the datagram transport a session sends its packets with.
+type transport interface {
+	WritePacket(b []byte) error
+	LocalAddr() net.Addr
+	RemoteAddr() net.Addr
+	Close() error
+}
+
+// connTransport sends packets over a connected net.Conn. It's used on the client side.
+type connTransport struct {
+	conn net.Conn
+}
+
+func (ct *connTransport) WritePacket(b []byte) error {
+	_, err := ct.conn.Write(b)
+	return err
+}
+
+func (ct *connTransport) LocalAddr() net.Addr {
+	return ct.conn.LocalAddr()
+}
+
+func (ct *connTransport) RemoteAddr() net.Addr {
+	return ct.conn.RemoteAddr()
+}
+
+func (ct *connTransport) Close() error {
+	return ct.conn.Close()
+}
+
+// packetConnTransport sends packets over a net.PacketConn to the peer's address. It's used on the
+// server side, where many sessions share the same net.PacketConn.
+type packetConnTransport struct {
+	conn    net.PacketConn
+	onClose func()
+
+	mu         sync.RWMutex
+	remoteAddr net.Addr
+}
+
+func (pct *packetConnTransport) WritePacket(b []byte) error {
+	_, err := pct.conn.WriteTo(b, pct.RemoteAddr())
+	return err
+}
+
+func (pct *packetConnTransport) LocalAddr() net.Addr {
+	return pct.conn.LocalAddr()
+}
+
+func (pct *packetConnTransport) RemoteAddr() net.Addr {
+	pct.mu.RLock()
+	defer pct.mu.RUnlock()
+	return pct.remoteAddr
+}
+
+func (pct *packetConnTransport) setRemoteAddr(addr net.Addr) {
+	pct.mu.Lock()
+	pct.remoteAddr = addr
+	pct.mu.Unlock()
+}
+
+func (pct *packetConnTransport) Close() error {
+	if pct.onClose != nil {
+		pct.onClose()
+	}
+	return nil
+}
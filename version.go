@@ -0,0 +1,98 @@
+package quic
+
+import (
+	"errors"
+
+	"github.com/simia-tech/go-quic/packet"
+)
+
+// Version defines a quic version. It converts from and to tags like "Q037" and IETF version
+// numbers. See packet.Version for details.
+type Version = packet.Version
+
+// Definition of the versions supported by this implementation.
+const (
+	VersionQ035 = packet.VersionQ035
+	VersionQ036 = packet.VersionQ036
+	VersionQ037 = packet.VersionQ037
+)
+
+// VersionInfo describes a version supported by this implementation.
+type VersionInfo struct {
+	Version Version
+
+	// Changes summarizes how this implementation treats the version compared to the previous one.
+	Changes string
+}
+
+// versionRegistry lists the versions supported by this implementation in descending order of
+// preference. Q038 and Q039 are missing, since the frame and packet codecs implement neither the
+// single byte padding frame of Q038 nor the big endian integers of Q039.
+var versionRegistry = []VersionInfo{
+	{VersionQ037, "The null encryption hash covers the sender's perspective."},
+	{VersionQ036, "Same wire format as Q035. Forcing head of line blocking would have to be " +
+		"requested in the crypto handshake, which isn't implemented."},
+	{VersionQ035, "Integers are written in little endian and padding frames extend to the end " +
+		"of the packet."},
+}
+
+// Errors returned by SetVersions.
+var (
+	ErrUnsupportedVersion = errors.New("quic: unsupported version")
+	ErrVersionInUse       = errors.New("quic: version already in use")
+)
+
+// SupportedVersions returns the versions supported by this implementation in descending order of
+// preference.
+func SupportedVersions() []Version {
+	versions := make([]Version, len(versionRegistry))
+	for index, info := range versionRegistry {
+		versions[index] = info.Version
+	}
+	return versions
+}
+
+// LookupVersion returns the description of the provided version. If the version isn't supported
+// by this implementation, false is returned.
+func LookupVersion(version Version) (VersionInfo, bool) {
+	for _, info := range versionRegistry {
+		if info.Version == version {
+			return info, true
+		}
+	}
+	return VersionInfo{}, false
+}
+
+// checkVersions returns a copy of the provided versions. If the list is empty or contains a
+// version that isn't supported by this implementation, ErrUnsupportedVersion is returned.
+func checkVersions(versions []Version) ([]Version, error) {
+	if len(versions) == 0 {
+		return nil, ErrUnsupportedVersion
+	}
+	for _, version := range versions {
+		if _, ok := LookupVersion(version); !ok {
+			return nil, ErrUnsupportedVersion
+		}
+	}
+	return append([]Version(nil), versions...), nil
+}
+
+// selectVersion returns the first of the preferred versions that is also offered by the peer. If
+// there's no common version, false is returned.
+func selectVersion(preferred, offered []Version) (Version, bool) {
+	for _, version := range preferred {
+		if containsVersion(offered, version) {
+			return version, true
+		}
+	}
+	return 0, false
+}
+
+func containsVersion(versions []Version, version Version) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package quic_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simia-tech/go-quic"
+	"github.com/simia-tech/go-quic/packet"
+)
+
+func TestSupportedVersions(t *testing.T) {
+	versions := quic.SupportedVersions()
+	assert.Equal(t, []quic.Version{
+		quic.VersionQ037, quic.VersionQ036, quic.VersionQ035,
+	}, versions)
+
+	// The returned list is a copy.
+	versions[0] = quic.VersionQ035
+	assert.Equal(t, quic.VersionQ037, quic.SupportedVersions()[0])
+}
+
+func TestLookupVersion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		version      quic.Version
+		expectString string
+		expectOK     bool
+	}{
+		{"Q037", quic.VersionQ037, "Q037", true},
+		{"Q035", quic.VersionQ035, "Q035", true},
+		{"Q039", packet.VersionQ039, "Q039", false},
+		{"IETF", quic.Version(0xff00001d), "0xff00001d", false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			info, ok := quic.LookupVersion(testCase.version)
+			assert.Equal(t, testCase.expectOK, ok)
+			assert.Equal(t, testCase.expectString, testCase.version.String())
+			if ok {
+				assert.Equal(t, testCase.version, info.Version)
+				assert.NotEmpty(t, info.Changes)
+			}
+		})
+	}
+}